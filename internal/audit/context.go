@@ -0,0 +1,39 @@
+// Package audit переносит через context сведения о том, кто и откуда меняет данные:
+// их кладут HTTP-middleware и фоновые задачи, а репозиторий записывает в журнал booking_audit.
+package audit
+
+import "context"
+
+// Служебные авторы изменений, которые вносятся не пользователем.
+const (
+	ActorSystem          = "system"           // фоновые задачи сервиса (например, истечение неоплаченных броней)
+	ActorPaymentProvider = "payment-provider" // уведомления платёжного провайдера
+)
+
+// Info — источник изменения. Пустые поля означают, что сведений нет.
+type Info struct {
+	ActorID   string
+	RequestID string
+	IP        string
+}
+
+type infoKey struct{}
+
+// WithInfo кладёт Info в контекст.
+func WithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, infoKey{}, info)
+}
+
+// WithActor возвращает контекст, в котором автором изменений считается actorID;
+// остальные сведения (ID запроса, IP) сохраняются.
+func WithActor(ctx context.Context, actorID string) context.Context {
+	info := FromContext(ctx)
+	info.ActorID = actorID
+	return WithInfo(ctx, info)
+}
+
+// FromContext возвращает Info из контекста (нулевое значение, если его нет).
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(infoKey{}).(Info)
+	return info
+}
@@ -0,0 +1,77 @@
+package clients
+
+import (
+	"sync"
+	"time"
+)
+
+type breakerState int
+
+const (
+	stateClosed   breakerState = iota // запросы идут как обычно
+	stateOpen                         // запросы сразу отклоняются до истечения cooldown
+	stateHalfOpen                     // пропускается один пробный запрос
+)
+
+// Breaker — простой circuit breaker по числу подряд идущих сбоев. После threshold сбоев
+// размыкается на cooldown, затем пропускает один пробный запрос: успех замыкает его, сбой — снова размыкает.
+type Breaker struct {
+	mu        sync.Mutex
+	state     breakerState
+	failures  int
+	openedAt  time.Time
+	threshold int
+	cooldown  time.Duration
+}
+
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Allow сообщает, можно ли отправить запрос. Разрешение в полуоткрытом состоянии
+// выдаётся одному вызывающему; результат нужно сообщить через Success или Failure.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case stateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = stateHalfOpen
+		return true
+	case stateHalfOpen:
+		return false // пробный запрос уже в пути
+	default:
+		return true
+	}
+}
+
+// Success отмечает успешный запрос и замыкает breaker.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = stateClosed
+	b.failures = 0
+}
+
+// Failure отмечает сбой зависимости; при достижении порога (или сбое пробного запроса) размыкает breaker.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state == stateHalfOpen || b.failures >= b.threshold {
+		b.state = stateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Release возвращает разрешение, выданное Allow, если исход запроса ничего не говорит о зависимости
+// (например, клиент отменил запрос). В полуоткрытом состоянии следующий Allow пропустит новый пробный запрос.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == stateHalfOpen {
+		b.state = stateOpen
+	}
+}
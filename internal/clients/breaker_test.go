@@ -0,0 +1,88 @@
+package clients
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreakerTransitions(t *testing.T) {
+	const cooldown = 20 * time.Millisecond
+	b := NewBreaker(3, cooldown)
+
+	// Замкнут: сбои ниже порога и успех, сбрасывающий счётчик, его не размыкают
+	for i := 0; i < 2; i++ {
+		if !b.Allow() {
+			t.Fatalf("closed breaker rejected request %d", i+1)
+		}
+		b.Failure()
+	}
+	b.Success()
+	for i := 0; i < 2; i++ {
+		b.Allow()
+		b.Failure()
+	}
+	if !b.Allow() {
+		t.Fatal("breaker opened before reaching the threshold of consecutive failures")
+	}
+
+	// Третий сбой подряд размыкает
+	b.Failure()
+	if b.Allow() {
+		t.Fatal("open breaker allowed a request before cooldown")
+	}
+
+	// После cooldown — один пробный запрос
+	time.Sleep(cooldown + 5*time.Millisecond)
+	if !b.Allow() {
+		t.Fatal("breaker did not allow a probe after cooldown")
+	}
+	if b.Allow() {
+		t.Fatal("half-open breaker allowed a second request while the probe is in flight")
+	}
+
+	// Сбой пробного запроса снова размыкает на полный cooldown
+	b.Failure()
+	if b.Allow() {
+		t.Fatal("breaker allowed a request right after a failed probe")
+	}
+	time.Sleep(cooldown + 5*time.Millisecond)
+	if !b.Allow() {
+		t.Fatal("breaker did not allow a probe after the second cooldown")
+	}
+
+	// Успешный пробный запрос замыкает
+	b.Success()
+	for i := 0; i < 3; i++ {
+		if !b.Allow() {
+			t.Fatalf("closed breaker rejected request %d after a successful probe", i+1)
+		}
+	}
+}
+
+func TestBreakerRelease(t *testing.T) {
+	const cooldown = 20 * time.Millisecond
+	b := NewBreaker(1, cooldown)
+	b.Allow()
+	b.Failure()
+	time.Sleep(cooldown + 5*time.Millisecond)
+
+	if !b.Allow() {
+		t.Fatal("breaker did not allow a probe after cooldown")
+	}
+	// Пробный запрос отменён клиентом: разрешение возвращается, следующий вызов может пробовать сразу
+	b.Release()
+	if !b.Allow() {
+		t.Fatal("breaker did not allow a new probe after the previous one was released")
+	}
+	if b.Allow() {
+		t.Fatal("breaker allowed two probes at once")
+	}
+
+	// В замкнутом состоянии Release ничего не меняет
+	b.Success()
+	b.Allow()
+	b.Release()
+	if !b.Allow() {
+		t.Fatal("Release opened a closed breaker")
+	}
+}
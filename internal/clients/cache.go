@@ -0,0 +1,211 @@
+package clients
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"expvar"
+	"sync"
+	"time"
+)
+
+// cacheMetrics — счётчики кэшей клиентов, доступные на /debug/vars как upstream_cache.
+// Ключи: "<имя>.hits", "<имя>.misses", "<имя>.shared" (запрос присоединился к уже идущему).
+var cacheMetrics = expvar.NewMap("upstream_cache")
+
+// CacheOptions — настройки кэша ответов соседнего сервиса.
+type CacheOptions struct {
+	Size        int           // максимум записей (LRU)
+	TTL         time.Duration // сколько хранится найденная сущность
+	NegativeTTL time.Duration // сколько хранится ответ 404
+}
+
+// lruCache — потокобезопасный LRU-кэш с истечением записей по времени.
+type lruCache[V any] struct {
+	mu    sync.Mutex
+	size  int
+	items map[string]*list.Element
+	order *list.List // в начале — недавно использованные
+}
+
+type cacheEntry[V any] struct {
+	key     string
+	value   V
+	err     error // закэшированный отрицательный ответ (ErrNotFound)
+	expires time.Time
+}
+
+func newLRUCache[V any](size int) *lruCache[V] {
+	return &lruCache[V]{size: size, items: map[string]*list.Element{}, order: list.New()}
+}
+
+// get возвращает значение или закэшированную ошибку; ok = false, если записи нет или она истекла.
+func (c *lruCache[V]) get(key string) (value V, err error, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, found := c.items[key]
+	if !found {
+		return value, nil, false
+	}
+	e := el.Value.(*cacheEntry[V])
+	if time.Now().After(e.expires) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return value, nil, false
+	}
+	c.order.MoveToFront(el)
+	return e.value, e.err, true
+}
+
+func (c *lruCache[V]) set(key string, value V, err error, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := &cacheEntry[V]{key: key, value: value, err: err, expires: time.Now().Add(ttl)}
+	if el, found := c.items[key]; found {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(e)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry[V]).key)
+	}
+}
+
+// flightGroup объединяет одновременные загрузки одного ключа в одну (аналог singleflight).
+type flightGroup[V any] struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall[V]
+}
+
+type flightCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// do вызывает fn для key, если загрузка этого ключа ещё не идёт, иначе ждёт её результата.
+// shared сообщает, что результат получен от чужого вызова.
+func (g *flightGroup[V]) do(key string, fn func() (V, error)) (value V, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*flightCall[V]{}
+	}
+	if call, found := g.calls[key]; found {
+		g.mu.Unlock()
+		<-call.done
+		return call.value, call.err, true
+	}
+	call := &flightCall[V]{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+	call.value, call.err = fn()
+	return call.value, call.err, false
+}
+
+type noCacheKey struct{}
+
+// NoCache помечает контекст: кэширующие клиенты загружают сущность заново, минуя кэш
+// (свежий ответ при этом сохраняется в кэш). Нужен там, где устаревшие до TTL данные недопустимы,
+// например при проверке владельца листинга.
+func NoCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+func noCache(ctx context.Context) bool {
+	v, _ := ctx.Value(noCacheKey{}).(bool)
+	return v
+}
+
+// cacheKey — ключ кэша для сущности id, запрошенной с заголовком Authorization authHeader.
+// Соседний сервис может отвечать разным вызывающим по-разному, поэтому ответ, полученный с одним
+// токеном, не отдаётся запросу с другим. В ключе хранится хэш заголовка, а не сам токен.
+func cacheKey(id, authHeader string) string {
+	if authHeader == "" {
+		return id
+	}
+	sum := sha256.Sum256([]byte(authHeader))
+	return id + "|" + hex.EncodeToString(sum[:16])
+}
+
+// cachedLoader — кэш поверх загрузчика: положительные ответы живут TTL, 404 — NegativeTTL,
+// прочие ошибки не кэшируются. Одновременные промахи по одному ключу делают один запрос.
+type cachedLoader[V any] struct {
+	name    string
+	opts    CacheOptions
+	cache   *lruCache[V]
+	flights flightGroup[V]
+}
+
+func newCachedLoader[V any](name string, opts CacheOptions) *cachedLoader[V] {
+	return &cachedLoader[V]{name: name, opts: opts, cache: newLRUCache[V](opts.Size)}
+}
+
+func (l *cachedLoader[V]) get(ctx context.Context, key string, load func(ctx context.Context) (V, error)) (V, error) {
+	if !noCache(ctx) {
+		if value, err, ok := l.cache.get(key); ok {
+			cacheMetrics.Add(l.name+".hits", 1)
+			return value, err
+		}
+	}
+	cacheMetrics.Add(l.name+".misses", 1)
+
+	value, err, shared := l.flights.do(key, func() (V, error) {
+		// Результат достанется и другим ожидающим, поэтому отмена запроса-инициатора не должна его обрывать
+		value, err := load(context.WithoutCancel(ctx))
+		switch {
+		case err == nil:
+			l.cache.set(key, value, nil, l.opts.TTL)
+		case errors.Is(err, ErrNotFound) && l.opts.NegativeTTL > 0:
+			l.cache.set(key, value, err, l.opts.NegativeTTL)
+		}
+		return value, err
+	})
+	if shared {
+		cacheMetrics.Add(l.name+".shared", 1)
+	}
+	return value, err
+}
+
+// CachedUserClient кэширует ответы UserClient отдельно для каждого заголовка Authorization.
+type CachedUserClient struct {
+	next   UserClient
+	loader *cachedLoader[*User]
+}
+
+func NewCachedUserClient(next UserClient, opts CacheOptions) *CachedUserClient {
+	return &CachedUserClient{next: next, loader: newCachedLoader[*User]("users", opts)}
+}
+
+func (c *CachedUserClient) GetUser(ctx context.Context, userID, authHeader string) (*User, error) {
+	return c.loader.get(ctx, cacheKey(userID, authHeader), func(ctx context.Context) (*User, error) {
+		return c.next.GetUser(ctx, userID, authHeader)
+	})
+}
+
+// CachedListingClient кэширует ответы ListingClient отдельно для каждого заголовка Authorization.
+type CachedListingClient struct {
+	next   ListingClient
+	loader *cachedLoader[*Listing]
+}
+
+func NewCachedListingClient(next ListingClient, opts CacheOptions) *CachedListingClient {
+	return &CachedListingClient{next: next, loader: newCachedLoader[*Listing]("listings", opts)}
+}
+
+func (c *CachedListingClient) GetListing(ctx context.Context, listingID, authHeader string) (*Listing, error) {
+	return c.loader.get(ctx, cacheKey(listingID, authHeader), func(ctx context.Context) (*Listing, error) {
+		return c.next.GetListing(ctx, listingID, authHeader)
+	})
+}
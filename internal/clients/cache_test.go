@@ -0,0 +1,246 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeListings — ListingClient в памяти: считает запросы и отвечает ошибкой err, если она задана.
+type fakeListings struct {
+	mu      sync.Mutex
+	calls   int
+	auths   []string
+	owner   string
+	err     error
+	release chan struct{} // если задан, ответ ждёт закрытия канала
+}
+
+func (f *fakeListings) GetListing(_ context.Context, listingID, authHeader string) (*Listing, error) {
+	f.mu.Lock()
+	f.calls++
+	f.auths = append(f.auths, authHeader)
+	owner, err, release := f.owner, f.err, f.release
+	f.mu.Unlock()
+	if release != nil {
+		<-release
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Listing{ID: listingID, OwnerID: owner}, nil
+}
+
+func (f *fakeListings) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func metric(name string) int64 {
+	if v, ok := cacheMetrics.Get(name).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestLRUCacheEviction(t *testing.T) {
+	c := newLRUCache[int](2)
+	c.set("a", 1, nil, time.Minute)
+	c.set("b", 2, nil, time.Minute)
+	c.get("a") // a становится недавно использованным
+	c.set("c", 3, nil, time.Minute)
+
+	if _, _, ok := c.get("b"); ok {
+		t.Fatal("least recently used entry was not evicted")
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		if v, _, ok := c.get(key); !ok || v != want {
+			t.Fatalf("get(%s) = %d, %v; want %d", key, v, ok, want)
+		}
+	}
+
+	// Перезапись существующего ключа не вытесняет соседей
+	c.set("a", 10, nil, time.Minute)
+	if v, _, ok := c.get("a"); !ok || v != 10 {
+		t.Fatalf("get(a) after overwrite = %d, %v; want 10", v, ok)
+	}
+	if _, _, ok := c.get("c"); !ok {
+		t.Fatal("overwrite evicted another entry")
+	}
+}
+
+func TestLRUCacheExpiry(t *testing.T) {
+	c := newLRUCache[int](10)
+	c.set("short", 1, nil, 10*time.Millisecond)
+	c.set("long", 2, nil, time.Minute)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, _, ok := c.get("short"); ok {
+		t.Fatal("expired entry returned")
+	}
+	if len(c.items) != 1 || c.order.Len() != 1 {
+		t.Fatalf("expired entry not removed: %d items, %d in order", len(c.items), c.order.Len())
+	}
+	if _, _, ok := c.get("long"); !ok {
+		t.Fatal("live entry missing")
+	}
+}
+
+func TestCachedListingClientHitsAndMisses(t *testing.T) {
+	next := &fakeListings{owner: "o-1"}
+	c := NewCachedListingClient(next, CacheOptions{Size: 10, TTL: time.Minute, NegativeTTL: time.Minute})
+	ctx := context.Background()
+	hits, misses := metric("listings.hits"), metric("listings.misses")
+
+	for i := 0; i < 3; i++ {
+		l, err := c.GetListing(ctx, "l-1", "Bearer a")
+		if err != nil || l.OwnerID != "o-1" {
+			t.Fatalf("GetListing #%d = %+v, %v", i+1, l, err)
+		}
+	}
+	if next.count() != 1 {
+		t.Fatalf("upstream called %d times, want 1", next.count())
+	}
+	if got := metric("listings.hits") - hits; got != 2 {
+		t.Fatalf("hits = %d, want 2", got)
+	}
+	if got := metric("listings.misses") - misses; got != 1 {
+		t.Fatalf("misses = %d, want 1", got)
+	}
+}
+
+func TestCachedListingClientSeparatesCallers(t *testing.T) {
+	next := &fakeListings{owner: "o-1"}
+	c := NewCachedListingClient(next, CacheOptions{Size: 10, TTL: time.Minute})
+	ctx := context.Background()
+
+	for _, auth := range []string{"Bearer a", "Bearer b", "", "Bearer a", "Bearer b", ""} {
+		if _, err := c.GetListing(ctx, "l-1", auth); err != nil {
+			t.Fatalf("GetListing(%q): %v", auth, err)
+		}
+	}
+	if next.count() != 3 {
+		t.Fatalf("upstream called %d times, want one per caller (3)", next.count())
+	}
+	want := []string{"Bearer a", "Bearer b", ""}
+	for i, auth := range next.auths {
+		if auth != want[i] {
+			t.Fatalf("upstream call %d with %q, want %q", i+1, auth, want[i])
+		}
+	}
+	for key := range c.loader.cache.items {
+		if strings.Contains(key, "Bearer") {
+			t.Fatalf("cache key %q contains the raw Authorization header", key)
+		}
+	}
+}
+
+func TestCachedListingClientNegativeCaching(t *testing.T) {
+	ctx := context.Background()
+
+	notFound := &fakeListings{err: fmt.Errorf("listing-service: %w", ErrNotFound)}
+	c := NewCachedListingClient(notFound, CacheOptions{Size: 10, TTL: time.Minute, NegativeTTL: 20 * time.Millisecond})
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetListing(ctx, "missing", ""); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("GetListing #%d = %v, want %v", i+1, err, ErrNotFound)
+		}
+	}
+	if notFound.count() != 1 {
+		t.Fatalf("404 was not cached: upstream called %d times", notFound.count())
+	}
+	time.Sleep(30 * time.Millisecond)
+	c.GetListing(ctx, "missing", "")
+	if notFound.count() != 2 {
+		t.Fatalf("404 outlived NegativeTTL: upstream called %d times, want 2", notFound.count())
+	}
+
+	// Без NegativeTTL 404 не кэшируется
+	c = NewCachedListingClient(notFound, CacheOptions{Size: 10, TTL: time.Minute})
+	c.GetListing(ctx, "missing", "")
+	c.GetListing(ctx, "missing", "")
+	if notFound.count() != 4 {
+		t.Fatalf("404 cached without NegativeTTL: upstream called %d times, want 4", notFound.count())
+	}
+
+	// Сбои соседа не кэшируются никогда
+	down := &fakeListings{err: fmt.Errorf("%w: listing-service returned status 503", ErrUnavailable)}
+	c = NewCachedListingClient(down, CacheOptions{Size: 10, TTL: time.Minute, NegativeTTL: time.Minute})
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetListing(ctx, "l-1", ""); !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("GetListing #%d = %v, want %v", i+1, err, ErrUnavailable)
+		}
+	}
+	if down.count() != 2 {
+		t.Fatalf("upstream failure was cached: upstream called %d times, want 2", down.count())
+	}
+}
+
+func TestCachedListingClientNoCache(t *testing.T) {
+	next := &fakeListings{owner: "o-1"}
+	c := NewCachedListingClient(next, CacheOptions{Size: 10, TTL: time.Minute})
+	ctx := context.Background()
+	c.GetListing(ctx, "l-1", "")
+
+	// Листинг передан другому владельцу: обычный запрос видит кэш, NoCache — свежие данные
+	next.mu.Lock()
+	next.owner = "o-2"
+	next.mu.Unlock()
+	if l, _ := c.GetListing(ctx, "l-1", ""); l.OwnerID != "o-1" {
+		t.Fatalf("cached owner = %s, want o-1", l.OwnerID)
+	}
+	if l, _ := c.GetListing(NoCache(ctx), "l-1", ""); l.OwnerID != "o-2" {
+		t.Fatalf("owner with NoCache = %s, want o-2", l.OwnerID)
+	}
+	// Свежий ответ обновил кэш
+	if l, _ := c.GetListing(ctx, "l-1", ""); l.OwnerID != "o-2" {
+		t.Fatalf("cached owner after refresh = %s, want o-2", l.OwnerID)
+	}
+	if next.count() != 2 {
+		t.Fatalf("upstream called %d times, want 2", next.count())
+	}
+}
+
+func TestCachedLoaderCollapsesConcurrentMisses(t *testing.T) {
+	const n = 10
+	next := &fakeListings{owner: "o-1", release: make(chan struct{})}
+	loader := newCachedLoader[*Listing]("listings-singleflight-test", CacheOptions{Size: 10, TTL: time.Minute})
+	misses, shared := metric("listings-singleflight-test.misses"), metric("listings-singleflight-test.shared")
+
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = loader.get(context.Background(), "l-1", func(ctx context.Context) (*Listing, error) {
+				return next.GetListing(ctx, "l-1", "")
+			})
+		}(i)
+	}
+	// Ждём, пока все промахнутся по кэшу, и отпускаем единственный запрос к соседу
+	deadline := time.Now().Add(5 * time.Second)
+	for metric("listings-singleflight-test.misses")-misses < n && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(next.release)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("caller %d: %v", i, err)
+		}
+	}
+	if next.count() != 1 {
+		t.Fatalf("upstream called %d times for concurrent misses, want 1", next.count())
+	}
+	if got := metric("listings-singleflight-test.shared") - shared; got != n-1 {
+		t.Fatalf("shared = %d, want %d", got, n-1)
+	}
+}
@@ -0,0 +1,16 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound — соседний сервис ответил 404: запрошенной сущности нет. Это ошибка валидации запроса.
+	ErrNotFound = errors.New("not found")
+	// ErrUnavailable — соседний сервис недоступен или ответил ошибкой (сеть, таймаут, 5xx, неожиданный статус).
+	ErrUnavailable = errors.New("upstream unavailable")
+	// ErrCircuitOpen — запрос не отправлялся: circuit breaker разомкнут после серии сбоев.
+	// Оборачивает ErrUnavailable.
+	ErrCircuitOpen = fmt.Errorf("%w: circuit breaker is open", ErrUnavailable)
+)
@@ -0,0 +1,148 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// Options — настройки HTTP-клиента соседнего сервиса.
+type Options struct {
+	Timeout          time.Duration // таймаут одной попытки
+	MaxAttempts      int           // всего попыток, включая первую
+	RetryBaseDelay   time.Duration // задержка перед первым повтором; дальше удваивается
+	RetryMaxDelay    time.Duration
+	BreakerThreshold int // сбоев подряд до размыкания
+	BreakerCooldown  time.Duration
+}
+
+// DefaultOptions — настройки по умолчанию.
+func DefaultOptions() Options {
+	return Options{
+		Timeout:          5 * time.Second,
+		MaxAttempts:      3,
+		RetryBaseDelay:   100 * time.Millisecond,
+		RetryMaxDelay:    time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// httpClient — общий транспорт для клиентов: GET JSON с повторами и circuit breaker.
+type httpClient struct {
+	name    string // имя сервиса для сообщений об ошибках
+	baseURL string
+	client  *http.Client
+	opts    Options
+	breaker *Breaker
+}
+
+func newHTTPClient(name, baseURL string, opts Options) *httpClient {
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 1
+	}
+	return &httpClient{
+		name:    name,
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: opts.Timeout},
+		opts:    opts,
+		breaker: NewBreaker(opts.BreakerThreshold, opts.BreakerCooldown),
+	}
+}
+
+// getJSON выполняет GET baseURL+path и декодирует ответ 200 в out.
+// 404 возвращается как ErrNotFound сразу; сетевые ошибки и 5xx повторяются с задержкой
+// (экспоненциальной, с full jitter) и после исчерпания попыток возвращаются как ErrUnavailable.
+func (c *httpClient) getJSON(ctx context.Context, path, authHeader string, out any) error {
+	var lastErr error
+	for attempt := 0; attempt < c.opts.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.retryDelay(attempt)); err != nil {
+				return fmt.Errorf("%w: %s: %v", ErrUnavailable, c.name, err)
+			}
+		}
+		retry, err := c.do(ctx, path, authHeader, out)
+		if err == nil || !retry {
+			return err
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+// do выполняет одну попытку и сообщает, имеет ли смысл её повторить.
+func (c *httpClient) do(ctx context.Context, path, authHeader string, out any) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", c.name, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+
+	if !c.breaker.Allow() {
+		return false, fmt.Errorf("%s: %w", c.name, ErrCircuitOpen)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// Отмена запроса клиентом — не сбой зависимости, повторять незачем
+		if ctx.Err() != nil {
+			c.breaker.Release()
+			return false, fmt.Errorf("%w: %s: %v", ErrUnavailable, c.name, err)
+		}
+		c.breaker.Failure()
+		return true, fmt.Errorf("%w: %s: %v", ErrUnavailable, c.name, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		c.breaker.Success()
+		if out == nil {
+			return false, nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return false, fmt.Errorf("%w: %s: invalid response body: %v", ErrUnavailable, c.name, err)
+		}
+		return false, nil
+	case resp.StatusCode == http.StatusNotFound:
+		c.breaker.Success()
+		return false, fmt.Errorf("%s: %w", c.name, ErrNotFound)
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		c.breaker.Failure()
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		return true, fmt.Errorf("%w: %s returned status %d", ErrUnavailable, c.name, resp.StatusCode)
+	default:
+		// Прочие 4xx (например, 401 на наш токен) повтором не лечатся, но зависимость при этом жива
+		c.breaker.Success()
+		return false, fmt.Errorf("%w: %s returned status %d", ErrUnavailable, c.name, resp.StatusCode)
+	}
+}
+
+// retryDelay — случайная задержка в [0, min(RetryMaxDelay, RetryBaseDelay·2^(attempt-1))] (full jitter).
+func (c *httpClient) retryDelay(attempt int) time.Duration {
+	d := c.opts.RetryBaseDelay << (attempt - 1)
+	if d > c.opts.RetryMaxDelay || d <= 0 {
+		d = c.opts.RetryMaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d + 1)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,181 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// upstream — тестовый сосед: отвечает статусами из statuses по очереди (последний — дальше всегда)
+// и запоминает число запросов и заголовок Authorization.
+type upstream struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	body     string
+	requests int
+	auth     string
+}
+
+func newUpstream(t *testing.T, body string, statuses ...int) *upstream {
+	u := &upstream{statuses: statuses, body: body}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u.mu.Lock()
+		status := u.statuses[min(u.requests, len(u.statuses)-1)]
+		u.requests++
+		u.auth = r.Header.Get("Authorization")
+		u.mu.Unlock()
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(u.body))
+		}
+	}))
+	t.Cleanup(u.Close)
+	return u
+}
+
+func (u *upstream) count() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.requests
+}
+
+func testOptions() Options {
+	return Options{
+		Timeout:          time.Second,
+		MaxAttempts:      3,
+		RetryBaseDelay:   time.Millisecond,
+		RetryMaxDelay:    5 * time.Millisecond,
+		BreakerThreshold: 100,
+		BreakerCooldown:  time.Minute,
+	}
+}
+
+func TestGetJSONStatuses(t *testing.T) {
+	cases := []struct {
+		name     string
+		statuses []int
+		body     string
+		want     error // nil — успех
+		requests int
+	}{
+		{"ok", []int{200}, `{"id":"l-1","owner_id":"o-1"}`, nil, 1},
+		{"retried 5xx then ok", []int{503, 500, 200}, `{"id":"l-1","owner_id":"o-1"}`, nil, 3},
+		{"retried 429 then ok", []int{429, 200}, `{"id":"l-1","owner_id":"o-1"}`, nil, 2},
+		{"5xx until attempts run out", []int{502}, "", ErrUnavailable, 3},
+		{"not found is not retried", []int{404}, "", ErrNotFound, 1},
+		{"other 4xx is not retried", []int{401}, "", ErrUnavailable, 1},
+		{"invalid body", []int{200}, `{"id":`, ErrUnavailable, 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := newUpstream(t, c.body, c.statuses...)
+			client := NewListingClient(srv.URL, testOptions())
+
+			l, err := client.GetListing(context.Background(), "l-1", "Bearer token")
+			if c.want == nil {
+				if err != nil {
+					t.Fatalf("GetListing: %v", err)
+				}
+				if l.ID != "l-1" || l.OwnerID != "o-1" {
+					t.Fatalf("listing = %+v", l)
+				}
+			} else if !errors.Is(err, c.want) {
+				t.Fatalf("GetListing error = %v, want %v", err, c.want)
+			}
+			if errors.Is(err, ErrNotFound) && errors.Is(err, ErrUnavailable) {
+				t.Fatalf("error %v is both not found and unavailable", err)
+			}
+			if got := srv.count(); got != c.requests {
+				t.Fatalf("upstream got %d requests, want %d", got, c.requests)
+			}
+			if srv.auth != "Bearer token" {
+				t.Fatalf("Authorization = %q, want the caller's header", srv.auth)
+			}
+		})
+	}
+}
+
+func TestGetJSONBreaker(t *testing.T) {
+	srv := newUpstream(t, `{"id":"u-1"}`, 500, 500, 200)
+	opts := testOptions()
+	opts.MaxAttempts = 1
+	opts.BreakerThreshold = 2
+	opts.BreakerCooldown = 20 * time.Millisecond
+	client := NewUserClient(srv.URL, opts)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetUser(ctx, "u-1", ""); !errors.Is(err, ErrUnavailable) || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: %v, want upstream failure", i+1, err)
+		}
+	}
+	// Порог достигнут: запрос не отправляется
+	if _, err := client.GetUser(ctx, "u-1", ""); !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("call with open breaker: %v, want %v", err, ErrCircuitOpen)
+	}
+	if got := srv.count(); got != 2 {
+		t.Fatalf("upstream got %d requests with open breaker, want 2", got)
+	}
+
+	// После cooldown пробный запрос успешен — breaker замыкается
+	time.Sleep(opts.BreakerCooldown + 5*time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetUser(ctx, "u-1", ""); err != nil {
+			t.Fatalf("call %d after cooldown: %v", i+1, err)
+		}
+	}
+	if got := srv.count(); got != 4 {
+		t.Fatalf("upstream got %d requests, want 4", got)
+	}
+}
+
+func TestGetJSONCancelledRetry(t *testing.T) {
+	srv := newUpstream(t, "", 503)
+	opts := testOptions()
+	opts.RetryBaseDelay, opts.RetryMaxDelay = time.Minute, time.Minute
+	client := NewListingClient(srv.URL, opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.GetListing(ctx, "l-1", "")
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("GetListing error = %v, want %v", err, ErrUnavailable)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("retry delay ignored context cancellation: took %s", elapsed)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	c := &httpClient{opts: Options{RetryBaseDelay: 100 * time.Millisecond, RetryMaxDelay: time.Second}}
+	for attempt, limit := range map[int]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		70: time.Second, // сдвиг переполняется — берётся максимум
+	} {
+		seen := map[time.Duration]bool{}
+		for i := 0; i < 200; i++ {
+			d := c.retryDelay(attempt)
+			if d < 0 || d > limit {
+				t.Fatalf("retryDelay(%d) = %s, want within [0, %s]", attempt, d, limit)
+			}
+			seen[d] = true
+		}
+		if len(seen) < 2 {
+			t.Fatalf("retryDelay(%d) has no jitter: always %v", attempt, seen)
+		}
+	}
+
+	c.opts = Options{}
+	if d := c.retryDelay(1); d != 0 {
+		t.Fatalf("retryDelay without delays = %s, want 0", d)
+	}
+}
@@ -0,0 +1,70 @@
+package clients
+
+import (
+	"context"
+	"net/url"
+)
+
+// Listing — листинг из listing-service (нужные нам поля). Владелец листинга берётся только отсюда.
+type Listing struct {
+	ID       string  `json:"id"`
+	OwnerID  string  `json:"owner_id"`
+	Title    string  `json:"title"`
+	Price    float64 `json:"price"`    // базовая цена за PriceUnit в Currency
+	Currency string  `json:"currency"` // ISO 4217
+	Capacity int     `json:"capacity"` // максимум гостей; 0 — не ограничено
+	MinStay  int     `json:"min_stay"` // минимум ночей; 0 — без ограничения
+	MaxStay  int     `json:"max_stay"` // максимум ночей; 0 — без ограничения
+
+	// Питомцы: без pets_allowed брони с питомцами не принимаются
+	PetsAllowed bool `json:"pets_allowed"`
+	MaxPets     int  `json:"max_pets"` // 0 — не ограничено
+
+	// Тарифы
+	PriceUnit         string             `json:"price_unit"`    // "night" (по умолчанию) или "hour"
+	WeekendPrice      float64            `json:"weekend_price"` // 0 — как Price
+	SeasonalPrices    []SeasonalPrice    `json:"seasonal_prices"`
+	CleaningFee       float64            `json:"cleaning_fee"`
+	TaxRate           float64            `json:"tax_rate"` // доля: 0.12 — 12%
+	LongStayDiscounts []LongStayDiscount `json:"long_stay_discounts"`
+}
+
+// SeasonalPrice — цена за единицу на даты From..To включительно (YYYY-MM-DD).
+type SeasonalPrice struct {
+	Name  string  `json:"name"`
+	From  string  `json:"from"`
+	To    string  `json:"to"`
+	Price float64 `json:"price"`
+}
+
+// LongStayDiscount — скидка Percent процентов при бронировании от MinUnits ночей (часов).
+type LongStayDiscount struct {
+	MinUnits int     `json:"min_units"`
+	Percent  float64 `json:"percent"`
+}
+
+// ListingClient — доступ к listing-service.
+type ListingClient interface {
+	// GetListing возвращает листинг или ошибку, оборачивающую ErrNotFound / ErrUnavailable.
+	GetListing(ctx context.Context, listingID, authHeader string) (*Listing, error)
+}
+
+// HTTPListingClient ходит в listing-service по HTTP: GET /api/listings/{listingID}.
+type HTTPListingClient struct {
+	http *httpClient
+}
+
+func NewListingClient(baseURL string, opts Options) *HTTPListingClient {
+	return &HTTPListingClient{http: newHTTPClient("listing-service", baseURL, opts)}
+}
+
+func (c *HTTPListingClient) GetListing(ctx context.Context, listingID, authHeader string) (*Listing, error) {
+	var l Listing
+	if err := c.http.getJSON(ctx, "/api/listings/"+url.PathEscape(listingID), authHeader, &l); err != nil {
+		return nil, err
+	}
+	if l.ID == "" {
+		l.ID = listingID
+	}
+	return &l, nil
+}
@@ -0,0 +1,37 @@
+package clients
+
+import (
+	"context"
+	"net/url"
+)
+
+// User — пользователь из user-service (нужные нам поля).
+type User struct {
+	ID string `json:"id"`
+}
+
+// UserClient — доступ к user-service.
+type UserClient interface {
+	// GetUser возвращает пользователя или ошибку, оборачивающую ErrNotFound / ErrUnavailable.
+	GetUser(ctx context.Context, userID, authHeader string) (*User, error)
+}
+
+// HTTPUserClient ходит в user-service по HTTP: GET /api/users/{userID}.
+type HTTPUserClient struct {
+	http *httpClient
+}
+
+func NewUserClient(baseURL string, opts Options) *HTTPUserClient {
+	return &HTTPUserClient{http: newHTTPClient("user-service", baseURL, opts)}
+}
+
+func (c *HTTPUserClient) GetUser(ctx context.Context, userID, authHeader string) (*User, error) {
+	var u User
+	if err := c.http.getJSON(ctx, "/api/users/"+url.PathEscape(userID), authHeader, &u); err != nil {
+		return nil, err
+	}
+	if u.ID == "" {
+		u.ID = userID
+	}
+	return &u, nil
+}
@@ -2,7 +2,11 @@ package config
 
 import (
 	"log"
+	"net/netip"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -18,6 +22,36 @@ type Config struct {
 	ListingServiceURL string
 	JWTSecret         string
 	HTTPPort          string
+	MigrateOnStart    bool
+	// Сколько хранится ключ Idempotency-Key и как часто удаляются истёкшие ключи
+	IdempotencyTTL           time.Duration
+	IdempotencySweepInterval time.Duration
+	// Дополнительный общий получатель всех событий из outbox (необязательно) и период опроса outbox
+	EventsWebhookURL   string
+	OutboxPollInterval time.Duration
+	// Доставка вебхуков подписчикам: период опроса, начальная задержка повтора и лимит попыток
+	WebhookPollInterval time.Duration
+	WebhookRetryBase    time.Duration
+	WebhookMaxAttempts  int
+	// Клиенты user-service и listing-service: таймаут попытки, число попыток и circuit breaker
+	UpstreamTimeout          time.Duration
+	UpstreamMaxAttempts      int
+	UpstreamBreakerThreshold int
+	UpstreamBreakerCooldown  time.Duration
+	// Кэш ответов user-service и listing-service: размеры (записей) и время жизни найденных и 404
+	UserCacheSize       int
+	ListingCacheSize    int
+	UpstreamCacheTTL    time.Duration
+	UpstreamNegativeTTL time.Duration
+	// Оплата: провайдер ("fake"), секрет подписи его webhook, срок оплаты брони и период проверки просроченных.
+	// Провайдер и секрет задаются явно — без них сервис не стартует (см. main.go).
+	PaymentProvider          string
+	PaymentWebhookSecret     string
+	PaymentFakeAutoAuthorize bool
+	PaymentTTL               time.Duration
+	PaymentExpiryInterval    time.Duration
+	// Прокси перед сервисом (IP или CIDR через запятую): только им верим в X-Forwarded-For/X-Real-IP
+	TrustedProxies []netip.Prefix
 }
 
 func LoadConfig() *Config {
@@ -27,16 +61,38 @@ func LoadConfig() *Config {
 	}
 
 	return &Config{
-		DBHost:            getEnv("DB_HOST", "localhost"),
-		DBPort:            getEnv("DB_PORT", "5432"),
-		DBUser:            getEnv("DB_USER", "postgres"),
-		DBPassword:        getEnv("DB_PASSWORD", ""),
-		DBName:            getEnv("DB_NAME", "bookingdb"),
-		DBSSLMode:         getEnv("DB_SSLMODE", "disable"),
-		UserServiceURL:    getEnv("USER_SERVICE_URL", ""),
-		ListingServiceURL: getEnv("LISTING_SERVICE_URL", ""),
-		JWTSecret:         getEnv("JWT_SECRET", ""),
-		HTTPPort:          getEnv("HTTP_PORT", "8080"),
+		DBHost:                   getEnv("DB_HOST", "localhost"),
+		DBPort:                   getEnv("DB_PORT", "5432"),
+		DBUser:                   getEnv("DB_USER", "postgres"),
+		DBPassword:               getEnv("DB_PASSWORD", ""),
+		DBName:                   getEnv("DB_NAME", "bookingdb"),
+		DBSSLMode:                getEnv("DB_SSLMODE", "disable"),
+		UserServiceURL:           getEnv("USER_SERVICE_URL", ""),
+		ListingServiceURL:        getEnv("LISTING_SERVICE_URL", ""),
+		JWTSecret:                getEnv("JWT_SECRET", ""),
+		HTTPPort:                 getEnv("HTTP_PORT", "8080"),
+		MigrateOnStart:           getEnv("MIGRATE_ON_START", "true") == "true",
+		IdempotencyTTL:           getDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		IdempotencySweepInterval: getDuration("IDEMPOTENCY_SWEEP_INTERVAL", 10*time.Minute),
+		EventsWebhookURL:         getEnv("EVENTS_WEBHOOK_URL", ""),
+		OutboxPollInterval:       getDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		WebhookPollInterval:      getDuration("WEBHOOK_POLL_INTERVAL", 5*time.Second),
+		WebhookRetryBase:         getDuration("WEBHOOK_RETRY_BASE", 30*time.Second),
+		WebhookMaxAttempts:       getInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		UpstreamTimeout:          getDuration("UPSTREAM_TIMEOUT", 5*time.Second),
+		UpstreamMaxAttempts:      getInt("UPSTREAM_MAX_ATTEMPTS", 3),
+		UpstreamBreakerThreshold: getInt("UPSTREAM_BREAKER_THRESHOLD", 5),
+		UpstreamBreakerCooldown:  getDuration("UPSTREAM_BREAKER_COOLDOWN", 30*time.Second),
+		UserCacheSize:            getInt("USER_CACHE_SIZE", 10000),
+		ListingCacheSize:         getInt("LISTING_CACHE_SIZE", 10000),
+		UpstreamCacheTTL:         getDuration("UPSTREAM_CACHE_TTL", time.Minute),
+		UpstreamNegativeTTL:      getDuration("UPSTREAM_NEGATIVE_CACHE_TTL", 15*time.Second),
+		PaymentProvider:          getEnv("PAYMENT_PROVIDER", ""),
+		PaymentWebhookSecret:     getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+		PaymentFakeAutoAuthorize: getEnv("PAYMENT_FAKE_AUTO_AUTHORIZE", "false") == "true",
+		PaymentTTL:               getDuration("PAYMENT_TTL", 15*time.Minute),
+		PaymentExpiryInterval:    getDuration("PAYMENT_EXPIRY_INTERVAL", time.Minute),
+		TrustedProxies:           getPrefixes("TRUSTED_PROXIES"),
 	}
 }
 
@@ -46,3 +102,53 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getDuration читает длительность в формате time.ParseDuration ("24h", "15m").
+// Некорректное или неположительное значение заменяется fallback с предупреждением в лог.
+func getDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("Warning: invalid %s=%q, using %s", key, v, fallback)
+		return fallback
+	}
+	return d
+}
+
+// getInt читает положительное целое; некорректное значение заменяется fallback с предупреждением в лог.
+func getInt(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		log.Printf("Warning: invalid %s=%q, using %d", key, v, fallback)
+		return fallback
+	}
+	return n
+}
+
+// getPrefixes читает список IP-адресов и CIDR через запятую; некорректные элементы пропускаются с предупреждением в лог.
+func getPrefixes(key string) []netip.Prefix {
+	var list []netip.Prefix
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if p, err := netip.ParsePrefix(item); err == nil {
+			list = append(list, p.Masked())
+			continue
+		}
+		if ip, err := netip.ParseAddr(item); err == nil {
+			list = append(list, netip.PrefixFrom(ip, ip.BitLen()))
+			continue
+		}
+		log.Printf("Warning: invalid %s entry %q, skipping", key, item)
+	}
+	return list
+}
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"booking-service/internal/model"
+)
+
+// EventPublisher доставляет доменное событие внешним потребителям.
+// Ошибка означает, что событие не доставлено и relay попробует ещё раз.
+type EventPublisher interface {
+	Publish(ctx context.Context, e model.OutboxEvent) error
+}
+
+// WebhookPublisher отправляет события POST-запросом с JSON-телом на заданный URL.
+type WebhookPublisher struct {
+	url        string
+	httpClient *http.Client
+}
+
+func NewWebhookPublisher(url string) *WebhookPublisher {
+	return &WebhookPublisher{
+		url:        url,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Publish отправляет событие и считает доставленным любой ответ 2xx.
+func (p *WebhookPublisher) Publish(ctx context.Context, e model.OutboxEvent) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("WebhookPublisher.Publish: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("WebhookPublisher.Publish: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", e.EventType)
+	req.Header.Set("X-Event-ID", strconv.FormatInt(e.ID, 10)) // потребитель может дедуплицировать по нему
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("WebhookPublisher.Publish: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("WebhookPublisher.Publish: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// MemoryPublisher складывает события в память. Подходит для тестов и локального запуска без потребителей.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []model.OutboxEvent
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(_ context.Context, e model.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, e)
+	return nil
+}
+
+// Events возвращает копию опубликованных событий в порядке публикации.
+func (p *MemoryPublisher) Events() []model.OutboxEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]model.OutboxEvent(nil), p.events...)
+}
+
+// MultiPublisher публикует событие во все publishers по очереди. При ошибке любого
+// relay повторит событие целиком, поэтому каждый publisher должен переносить повторы.
+type MultiPublisher []EventPublisher
+
+func (m MultiPublisher) Publish(ctx context.Context, e model.OutboxEvent) error {
+	for _, p := range m {
+		if err := p.Publish(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"booking-service/internal/model"
+)
+
+const relayBatchSize = 100
+
+// Outbox — источник неопубликованных событий. Реализуется repository.OutboxRepository.
+type Outbox interface {
+	ProcessPending(ctx context.Context, limit int, publish func(ctx context.Context, e model.OutboxEvent) error) (int, error)
+}
+
+// Relay периодически забирает события из outbox и публикует их через EventPublisher.
+// Доставка «хотя бы один раз»: при сбое после публикации событие может уйти повторно.
+type Relay struct {
+	outbox    Outbox
+	publisher EventPublisher
+	interval  time.Duration
+}
+
+func NewRelay(outbox Outbox, publisher EventPublisher, interval time.Duration) *Relay {
+	return &Relay{outbox: outbox, publisher: publisher, interval: interval}
+}
+
+// Run публикует события каждые interval, пока не отменён ctx. Если из батча что-то опубликовано,
+// следующий забирается сразу, не дожидаясь тика: в нём могут быть следующие события тех же броней
+// (за один батч публикуется не больше одного события брони) или остаток полного батча.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		n, err := r.outbox.ProcessPending(ctx, relayBatchSize, r.publisher.Publish)
+		if err != nil {
+			log.Printf("outbox relay: %v\n", err)
+		}
+		if err == nil && n > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
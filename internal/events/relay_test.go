@@ -0,0 +1,83 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"booking-service/internal/model"
+	"booking-service/internal/repository"
+	"booking-service/internal/testdb"
+	"github.com/google/uuid"
+)
+
+// TestRelayPublishesOutboxOnce проверяет, что события Create и UpdateStatus доходят из outbox
+// до MemoryPublisher ровно по одному разу и помечаются опубликованными.
+func TestRelayPublishesOutboxOnce(t *testing.T) {
+	db := testdb.Open(t)
+	bookings := repository.NewBookingRepository(db)
+	ctx := context.Background()
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	b := &model.Booking{
+		ListingID: "test-listing-" + uuid.NewString(),
+		UserID:    "test-user",
+		OwnerID:   "test-owner",
+		StartTime: start,
+		EndTime:   start.Add(2 * time.Hour),
+		Status:    model.StatusPending,
+		Adults:    1,
+	}
+	if err := bookings.Create(ctx, b); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := bookings.UpdateStatus(ctx, b.ID, []string{model.StatusPending}, model.StatusConfirmed); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+
+	pub := NewMemoryPublisher()
+	relay := NewRelay(repository.NewOutboxRepository(db), pub, 10*time.Millisecond)
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(runCtx)
+	}()
+
+	// В базе могут быть события других тестов — смотрим только на события этой брони
+	ours := func() []model.OutboxEvent {
+		var list []model.OutboxEvent
+		for _, e := range pub.Events() {
+			if e.AggregateID == b.ID {
+				list = append(list, e)
+			}
+		}
+		return list
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(ours()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Ещё несколько тиков relay: опубликованные события не должны уйти повторно
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-done
+
+	got := ours()
+	if len(got) != 2 {
+		t.Fatalf("published %d events for the booking, want 2: %+v", len(got), got)
+	}
+	if got[0].EventType != model.EventBookingCreated || got[1].EventType != model.EventBookingConfirmed {
+		t.Fatalf("events = %s, %s; want %s, %s",
+			got[0].EventType, got[1].EventType, model.EventBookingCreated, model.EventBookingConfirmed)
+	}
+
+	var unpublished int
+	query := "SELECT count(*) FROM outbox_events WHERE aggregate_id = $1 AND published_at IS NULL"
+	if err := db.Get(&unpublished, query, b.ID); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if unpublished != 0 {
+		t.Fatalf("%d events of the booking are not marked as published", unpublished)
+	}
+}
@@ -3,95 +3,139 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"io"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	"booking-service/internal/middleware"
+	"booking-service/internal/model"
+	"booking-service/internal/problem"
 	"booking-service/internal/service"
 )
 
 type BookingHandler struct {
-	svc *service.BookingService
+	svc        *service.BookingService
+	idempotent func(http.Handler) http.Handler // middleware.Idempotency для создающих запросов
 }
 
-func NewBookingHandler(svc *service.BookingService) *BookingHandler {
-	return &BookingHandler{svc: svc}
+func NewBookingHandler(svc *service.BookingService, idempotent func(http.Handler) http.Handler) *BookingHandler {
+	return &BookingHandler{svc: svc, idempotent: idempotent}
 }
 
 func (h *BookingHandler) RegisterRoutes(r chi.Router) {
+	// Политики доступа по ролям
+	adminOnly := middleware.RequireRole(middleware.RoleAdmin)
+	ownerOrAdmin := middleware.RequireRole(middleware.RoleOwner, middleware.RoleAdmin)
+
 	r.Route("/bookings", func(r chi.Router) {
-		r.Get("/", h.listAllBookings)
-		r.Post("/", h.createBooking)                     // POST   /bookings
-		r.Get("/{bookingID}", h.getBookingByID)          // GET    /bookings/{bookingID}
-		r.Get("/user/{userID}", h.listBookingsByUser)    // GET    /bookings/user/{userID}
+		r.With(adminOnly).Get("/", h.listAllBookings)                         // GET    /bookings (admin)
+		r.With(h.idempotent).Post("/", h.createBooking)                       // POST   /bookings (Idempotency-Key)
+		r.Post("/quote", h.quoteBooking)                                      // POST   /bookings/quote
+		r.Get("/{bookingID}", h.getBookingByID)                               // GET    /bookings/{bookingID}
+		r.Patch("/{bookingID}", h.modifyBooking)                              // PATCH  /bookings/{bookingID}
+		r.Get("/{bookingID}/modifications", h.listModifications)              // GET    /bookings/{bookingID}/modifications
+		r.With(ownerOrAdmin).Get("/{bookingID}/history", h.getBookingHistory) // GET    /bookings/{bookingID}/history
+		r.With(ownerOrAdmin).Post("/{bookingID}/confirm", h.confirmBooking)   // POST   /bookings/{bookingID}/confirm
+		r.With(ownerOrAdmin).Post("/{bookingID}/reject", h.rejectBooking)     // POST   /bookings/{bookingID}/reject
+		r.Post("/{bookingID}/cancel", h.cancelBooking)                        // POST   /bookings/{bookingID}/cancel
+		r.Get("/{bookingID}/cancellation-preview", h.previewCancellation)     // GET    /bookings/{bookingID}/cancellation-preview
+		r.With(ownerOrAdmin).Post("/{bookingID}/complete", h.completeBooking)
+		r.Get("/user/{userID}", h.listBookingsByUser) // GET    /bookings/user/{userID}
+		r.With(ownerOrAdmin).Get("/owner/{ownerID}", h.listBookingsByOwner)
 		r.Get("/available", h.checkAvailabilityInterval) // GET    /bookings/available?listing_id=...&start=...&end=...
 		r.Get("/availability/{listingID}", h.getDailyAvailability)
 		r.Get("/available/{listingID}", h.checkAvailabilityAt) // GET    /bookings/available/{listingID}?at=...
 	})
+
+	// GET /listings/{listingID}/bookings — лента броней листинга для его владельца
+	r.With(ownerOrAdmin).Get("/listings/{listingID}/bookings", h.listBookingsByListing)
 }
 
 // createBooking обрабатывает POST /bookings
 func (h *BookingHandler) createBooking(w http.ResponseWriter, r *http.Request) {
-	// 1) Извлекаем заголовок Authorization
+	// 1) Извлекаем заголовок Authorization и вызывающего пользователя из JWT
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
-		http.Error(w, "Missing Authorization header", http.StatusUnauthorized)
+		writeProblem(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Missing Authorization header")
+		return
+	}
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Missing caller identity")
 		return
 	}
-	log.Printf("DEBUG: incoming Authorization header = %q\n", authHeader)
 
-	// 2) Читаем тело запроса
+	// 2) Читаем тело запроса. user_id необязателен: бронь всегда создаётся от имени владельца токена.
+	//    owner_id тоже необязателен: владелец берётся из listing-service, переданный — лишь сверяется.
 	var reqBody struct {
 		ListingID string `json:"listing_id"`
 		UserID    string `json:"user_id"`
 		OwnerID   string `json:"owner_id"`
 		StartTime string `json:"start_time"`
 		EndTime   string `json:"end_time"`
+
+		Adults          int            `json:"adults"`
+		Children        int            `json:"children"`
+		Pets            int            `json:"pets"`
+		SpecialRequests string         `json:"special_requests"`
+		Metadata        model.Metadata `json:"metadata"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
-		http.Error(w, "Invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid JSON body")
+		return
+	}
+	if reqBody.UserID != "" && reqBody.UserID != principal.UserID {
+		writeProblem(w, r, http.StatusForbidden, problem.CodeForbidden, "user_id does not match the authenticated user")
 		return
 	}
 
 	// 3) Парсим даты в time.Time (RFC3339)
 	start, err := time.Parse(time.RFC3339, reqBody.StartTime)
 	if err != nil {
-		http.Error(w, "Invalid start_time format (RFC3339 expected)", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid start_time format (RFC3339 expected)")
 		return
 	}
 	end, err := time.Parse(time.RFC3339, reqBody.EndTime)
 	if err != nil {
-		http.Error(w, "Invalid end_time format (RFC3339 expected)", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid end_time format (RFC3339 expected)")
 		return
 	}
 
 	// 4) Проверяем, что userID и ownerID имеют корректный UUID-формат
-	if _, err := uuid.Parse(reqBody.UserID); err != nil {
-		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+	if _, err := uuid.Parse(principal.UserID); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid user_id")
 		return
 	}
-	if _, err := uuid.Parse(reqBody.OwnerID); err != nil {
-		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+	if _, err := uuid.Parse(reqBody.OwnerID); reqBody.OwnerID != "" && err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid owner_id")
 		return
 	}
 
 	// 5) Готовим запрос для сервисного слоя, пробрасывая authHeader
 	svcReq := &service.CreateBookingRequest{
 		ListingID:  reqBody.ListingID, // treated as plain string (text)
-		UserID:     reqBody.UserID,
+		UserID:     principal.UserID,
 		OwnerID:    reqBody.OwnerID,
 		StartTime:  start,
 		EndTime:    end,
 		AuthHeader: authHeader, // "Bearer <token>"
+
+		Adults:          reqBody.Adults,
+		Children:        reqBody.Children,
+		Pets:            reqBody.Pets,
+		SpecialRequests: reqBody.SpecialRequests,
+		Metadata:        reqBody.Metadata,
 	}
 
 	booking, err := h.svc.CreateBooking(r.Context(), svcReq)
 	if err != nil {
-		http.Error(w, "Could not create booking: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
@@ -100,17 +144,104 @@ func (h *BookingHandler) createBooking(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(booking)
 }
 
+// quoteBooking обрабатывает POST /bookings/quote: расчёт цены без создания брони
+func (h *BookingHandler) quoteBooking(w http.ResponseWriter, r *http.Request) {
+	var reqBody struct {
+		ListingID string `json:"listing_id"`
+		StartTime string `json:"start_time"`
+		EndTime   string `json:"end_time"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid JSON body")
+		return
+	}
+	if reqBody.ListingID == "" {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Missing listing_id")
+		return
+	}
+	start, err := time.Parse(time.RFC3339, reqBody.StartTime)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid start_time format (RFC3339 expected)")
+		return
+	}
+	end, err := time.Parse(time.RFC3339, reqBody.EndTime)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid end_time format (RFC3339 expected)")
+		return
+	}
+
+	quote, err := h.svc.QuoteBooking(r.Context(), reqBody.ListingID, start, end, r.Header.Get("Authorization"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quote)
+}
+
 // getBookingByID обрабатывает GET /bookings/{bookingID}
 func (h *BookingHandler) getBookingByID(w http.ResponseWriter, r *http.Request) {
 	bookingID := chi.URLParam(r, "bookingID")
 	if _, err := uuid.Parse(bookingID); err != nil {
-		http.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid booking ID")
+		return
+	}
+
+	booking, err := h.svc.GetBookingByID(r.Context(), bookingID, actorFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(booking)
+}
+
+// modifyBooking обрабатывает PATCH /bookings/{bookingID}
+func (h *BookingHandler) modifyBooking(w http.ResponseWriter, r *http.Request) {
+	bookingID := chi.URLParam(r, "bookingID")
+	if _, err := uuid.Parse(bookingID); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid booking ID")
 		return
 	}
 
-	booking, err := h.svc.GetBookingByID(r.Context(), bookingID)
+	// Можно передать только одну из границ — вторая останется прежней
+	var reqBody struct {
+		StartTime string `json:"start_time"`
+		EndTime   string `json:"end_time"`
+		Reason    string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid JSON body")
+		return
+	}
+	if reqBody.StartTime == "" && reqBody.EndTime == "" {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "start_time or end_time is required")
+		return
+	}
+
+	svcReq := &service.ModifyBookingRequest{
+		Reason:     reqBody.Reason,
+		AuthHeader: r.Header.Get("Authorization"),
+	}
+	var err error
+	if reqBody.StartTime != "" {
+		if svcReq.StartTime, err = time.Parse(time.RFC3339, reqBody.StartTime); err != nil {
+			writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid start_time format (RFC3339 expected)")
+			return
+		}
+	}
+	if reqBody.EndTime != "" {
+		if svcReq.EndTime, err = time.Parse(time.RFC3339, reqBody.EndTime); err != nil {
+			writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid end_time format (RFC3339 expected)")
+			return
+		}
+	}
+
+	booking, err := h.svc.ModifyBooking(r.Context(), bookingID, actorFromRequest(r), svcReq)
 	if err != nil {
-		http.Error(w, "Booking not found: "+err.Error(), http.StatusNotFound)
+		writeError(w, r, err)
 		return
 	}
 
@@ -118,22 +249,111 @@ func (h *BookingHandler) getBookingByID(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(booking)
 }
 
+// listModifications обрабатывает GET /bookings/{bookingID}/modifications
+func (h *BookingHandler) listModifications(w http.ResponseWriter, r *http.Request) {
+	bookingID := chi.URLParam(r, "bookingID")
+	if _, err := uuid.Parse(bookingID); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid booking ID")
+		return
+	}
+
+	list, err := h.svc.ListModifications(r.Context(), bookingID, actorFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// getBookingHistory обрабатывает GET /bookings/{bookingID}/history
+func (h *BookingHandler) getBookingHistory(w http.ResponseWriter, r *http.Request) {
+	bookingID := chi.URLParam(r, "bookingID")
+	if _, err := uuid.Parse(bookingID); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid booking ID")
+		return
+	}
+
+	history, err := h.svc.GetBookingHistory(r.Context(), bookingID, actorFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
 // listBookingsByUser обрабатывает GET /bookings/user/{userID}
 func (h *BookingHandler) listBookingsByUser(w http.ResponseWriter, r *http.Request) {
 	userID := chi.URLParam(r, "userID")
 	if _, err := uuid.Parse(userID); err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid user ID")
+		return
+	}
+	// Пользователь может смотреть только свои брони (администратор — любые)
+	if actor := actorFromRequest(r); !actor.Admin && actor.UserID != userID {
+		writeProblem(w, r, http.StatusForbidden, problem.CodeForbidden, "You can only list your own bookings")
 		return
 	}
 
-	list, err := h.svc.ListBookingsByUser(r.Context(), userID)
+	filter, err := parseListFilter(r)
 	if err != nil {
-		http.Error(w, "Error fetching bookings: "+err.Error(), http.StatusInternalServerError)
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidFilter, err.Error())
+		return
+	}
+
+	page, err := h.svc.ListBookingsByUser(r.Context(), userID, filter)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(list)
+	json.NewEncoder(w).Encode(page)
+}
+
+// listBookingsByOwner обрабатывает GET /bookings/owner/{ownerID}
+func (h *BookingHandler) listBookingsByOwner(w http.ResponseWriter, r *http.Request) {
+	ownerID := chi.URLParam(r, "ownerID")
+	if _, err := uuid.Parse(ownerID); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid owner ID")
+		return
+	}
+	filter, err := parseListFilter(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidFilter, err.Error())
+		return
+	}
+
+	page, err := h.svc.ListBookingsByOwner(r.Context(), ownerID, actorFromRequest(r), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// listBookingsByListing обрабатывает GET /listings/{listingID}/bookings
+func (h *BookingHandler) listBookingsByListing(w http.ResponseWriter, r *http.Request) {
+	listingID := chi.URLParam(r, "listingID")
+	filter, err := parseListFilter(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidFilter, err.Error())
+		return
+	}
+
+	page, err := h.svc.ListBookingsByListing(r.Context(), listingID, actorFromRequest(r), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
 }
 
 // checkAvailabilityInterval обрабатывает GET /bookings/available?listing_id=...&start=...&end=...
@@ -143,25 +363,25 @@ func (h *BookingHandler) checkAvailabilityInterval(w http.ResponseWriter, r *htt
 	endStr := r.URL.Query().Get("end")
 
 	if listingID == "" || startStr == "" || endStr == "" {
-		http.Error(w, "Missing listing_id, start or end query parameters", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Missing listing_id, start or end query parameters")
 		return
 	}
 
 	// Парсим даты
 	start, err := time.Parse(time.RFC3339, startStr)
 	if err != nil {
-		http.Error(w, "Invalid start format (RFC3339 expected)", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid start format (RFC3339 expected)")
 		return
 	}
 	end, err := time.Parse(time.RFC3339, endStr)
 	if err != nil {
-		http.Error(w, "Invalid end format (RFC3339 expected)", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid end format (RFC3339 expected)")
 		return
 	}
 
 	available, err := h.svc.IsAvailableInterval(r.Context(), listingID, start, end)
 	if err != nil {
-		http.Error(w, "Error checking availability: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
@@ -175,60 +395,183 @@ func (h *BookingHandler) checkAvailabilityAt(w http.ResponseWriter, r *http.Requ
 	atStr := r.URL.Query().Get("at")
 
 	if listingID == "" || atStr == "" {
-		http.Error(w, "Missing listingID or 'at' query parameter", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Missing listingID or 'at' query parameter")
 		return
 	}
 
 	timePoint, err := time.Parse(time.RFC3339, atStr)
 	if err != nil {
-		http.Error(w, "Invalid 'at' format (RFC3339 expected)", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid 'at' format (RFC3339 expected)")
 		return
 	}
 
 	available, err := h.svc.IsAvailableAtMoment(r.Context(), listingID, timePoint)
 	if err != nil {
-		http.Error(w, "Error checking availability: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]bool{"available": available})
 }
+
+// getDailyAvailability обрабатывает GET /bookings/availability/{listingID}?date=YYYY-MM-DD[&tz=...]
+// и GET /bookings/availability/{listingID}?from=YYYY-MM-DD&to=YYYY-MM-DD[&tz=...] для диапазона дней.
 func (h *BookingHandler) getDailyAvailability(w http.ResponseWriter, r *http.Request) {
 	listingID := chi.URLParam(r, "listingID")
-	dateStr := r.URL.Query().Get("date")
+	q := r.URL.Query()
+	if q.Get("from") != "" || q.Get("to") != "" {
+		h.getAvailabilityRange(w, r, listingID)
+		return
+	}
+
+	dateStr := q.Get("date")
 	if dateStr == "" {
-		http.Error(w, "Missing 'date' query parameter", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Missing 'date' (or 'from' and 'to') query parameter")
 		return
 	}
 
 	// Проверим формат dateStr: "YYYY-MM-DD"
 	if _, err := time.Parse("2006-01-02", dateStr); err != nil {
-		http.Error(w, "Invalid date format (expected YYYY-MM-DD)", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid date format (expected YYYY-MM-DD)")
 		return
 	}
 
-	hoursMap, err := h.svc.DailyAvailability(r.Context(), listingID, dateStr)
+	day, err := h.svc.DailyAvailability(r.Context(), listingID, dateStr, q.Get("tz"))
 	if err != nil {
-		http.Error(w, "Error getting availability: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(day)
+}
+
+func (h *BookingHandler) getAvailabilityRange(w http.ResponseWriter, r *http.Request, listingID string) {
+	q := r.URL.Query()
+	from, to := q.Get("from"), q.Get("to")
+	if from == "" || to == "" {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Both 'from' and 'to' query parameters are required")
 		return
 	}
 
-	// Формируем ответ
-	resp := map[string]interface{}{
-		"date":  dateStr,
-		"hours": hoursMap,
+	res, err := h.svc.AvailabilityRange(r.Context(), listingID, from, to, q.Get("tz"))
+	if err != nil {
+		writeError(w, r, err)
+		return
 	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+	json.NewEncoder(w).Encode(res)
 }
+
 func (h *BookingHandler) listAllBookings(w http.ResponseWriter, r *http.Request) {
-	bookings, err := h.svc.ListAllBookings(r.Context())
+	filter, err := parseListFilter(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidFilter, err.Error())
+		return
+	}
+
+	page, err := h.svc.ListAllBookings(r.Context(), filter)
 	if err != nil {
-		http.Error(w, "Error fetching all bookings: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(bookings)
+	json.NewEncoder(w).Encode(page)
+}
+
+// confirmBooking обрабатывает POST /bookings/{bookingID}/confirm
+func (h *BookingHandler) confirmBooking(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.svc.ConfirmBooking)
+}
+
+// rejectBooking обрабатывает POST /bookings/{bookingID}/reject
+func (h *BookingHandler) rejectBooking(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.svc.RejectBooking)
+}
+
+// cancelBookingRequest — необязательное тело POST /bookings/{bookingID}/cancel.
+type cancelBookingRequest struct {
+	Reason string `json:"reason"`
+}
+
+// cancelBooking обрабатывает POST /bookings/{bookingID}/cancel
+func (h *BookingHandler) cancelBooking(w http.ResponseWriter, r *http.Request) {
+	bookingID := chi.URLParam(r, "bookingID")
+	if _, err := uuid.Parse(bookingID); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid booking ID")
+		return
+	}
+
+	// Тело необязательно: без него бронь отменяется без указания причины
+	var req cancelBookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid JSON body")
+		return
+	}
+
+	result, err := h.svc.CancelBooking(r.Context(), bookingID, actorFromRequest(r), req.Reason)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// previewCancellation обрабатывает GET /bookings/{bookingID}/cancellation-preview
+func (h *BookingHandler) previewCancellation(w http.ResponseWriter, r *http.Request) {
+	bookingID := chi.URLParam(r, "bookingID")
+	if _, err := uuid.Parse(bookingID); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid booking ID")
+		return
+	}
+
+	refund, err := h.svc.PreviewCancellation(r.Context(), bookingID, actorFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(refund)
+}
+
+// completeBooking обрабатывает POST /bookings/{bookingID}/complete
+func (h *BookingHandler) completeBooking(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.svc.CompleteBooking)
+}
+
+// changeStatus — общий код для эндпоинтов смены статуса брони.
+func (h *BookingHandler) changeStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	apply func(ctx context.Context, bookingID string, actor service.Actor) (*model.Booking, error),
+) {
+	bookingID := chi.URLParam(r, "bookingID")
+	if _, err := uuid.Parse(bookingID); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid booking ID")
+		return
+	}
+
+	booking, err := apply(r.Context(), bookingID, actorFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(booking)
+}
+
+// actorFromRequest строит service.Actor из Principal, положенного JWT-middleware.
+func actorFromRequest(r *http.Request) service.Actor {
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	return service.Actor{
+		UserID: principal.UserID,
+		Admin:  principal.HasRole(middleware.RoleAdmin),
+	}
 }
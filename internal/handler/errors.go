@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"booking-service/internal/problem"
+	"booking-service/internal/repository"
+	"booking-service/internal/service"
+)
+
+// errorMapping — соответствие ошибки сервисного/репозиторного слоя HTTP-статусу и коду API.
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// errorMappings — единственное место, где ошибки превращаются в HTTP-ответы.
+// Порядок важен: берётся первое совпадение по errors.Is.
+var errorMappings = []errorMapping{
+	{repository.ErrNotFound, http.StatusNotFound, problem.CodeBookingNotFound},
+	{repository.ErrSlotTaken, http.StatusConflict, problem.CodeSlotTaken},
+	{repository.ErrStatusConflict, http.StatusConflict, problem.CodeInvalidTransition},
+	{repository.ErrInvalidCursor, http.StatusBadRequest, problem.CodeInvalidCursor},
+	{repository.ErrInvalidFilter, http.StatusBadRequest, problem.CodeInvalidFilter},
+	{repository.ErrSubscriptionNotFound, http.StatusNotFound, problem.CodeWebhookNotFound},
+	{repository.ErrDeliveryNotFound, http.StatusNotFound, problem.CodeDeliveryNotFound},
+	{repository.ErrPaymentNotFound, http.StatusNotFound, problem.CodePaymentNotFound},
+
+	{service.ErrForbidden, http.StatusForbidden, problem.CodeForbidden},
+	{service.ErrInvalidTransition, http.StatusConflict, problem.CodeInvalidTransition},
+	{service.ErrInvalidTimeRange, http.StatusBadRequest, problem.CodeInvalidTimeRange},
+	{service.ErrStartInPast, http.StatusUnprocessableEntity, problem.CodeStartInPast},
+	{service.ErrInsufficientNotice, http.StatusUnprocessableEntity, problem.CodeInsufficientNotice},
+	{service.ErrBeyondHorizon, http.StatusUnprocessableEntity, problem.CodeBeyondHorizon},
+	{service.ErrStayTooShort, http.StatusUnprocessableEntity, problem.CodeStayTooShort},
+	{service.ErrStayTooLong, http.StatusUnprocessableEntity, problem.CodeStayTooLong},
+	{service.ErrCheckInDayNotAllowed, http.StatusUnprocessableEntity, problem.CodeCheckInDayNotAllowed},
+	{service.ErrCheckInTimeMismatch, http.StatusUnprocessableEntity, problem.CodeCheckInTimeMismatch},
+	{service.ErrCheckOutTimeMismatch, http.StatusUnprocessableEntity, problem.CodeCheckOutTimeMismatch},
+	{service.ErrListingNotFound, http.StatusNotFound, problem.CodeListingNotFound},
+	{service.ErrUserNotFound, http.StatusUnprocessableEntity, problem.CodeUserNotFound},
+	{service.ErrOwnerNotFound, http.StatusUnprocessableEntity, problem.CodeOwnerNotFound},
+	{service.ErrOwnerMismatch, http.StatusUnprocessableEntity, problem.CodeOwnerMismatch},
+	{service.ErrInvalidGuests, http.StatusBadRequest, problem.CodeInvalidGuests},
+	{service.ErrCapacityExceeded, http.StatusUnprocessableEntity, problem.CodeCapacityExceeded},
+	{service.ErrPetsNotAllowed, http.StatusUnprocessableEntity, problem.CodePetsNotAllowed},
+	{service.ErrPricingUnavailable, http.StatusUnprocessableEntity, problem.CodePricingUnavailable},
+	{service.ErrPaymentDeclined, http.StatusPaymentRequired, problem.CodePaymentDeclined},
+	{service.ErrInvalidPaymentWebhook, http.StatusBadRequest, problem.CodeInvalidPaymentWebhook},
+	{service.ErrUpstreamUnavailable, http.StatusServiceUnavailable, problem.CodeUpstreamFailure},
+	{service.ErrInvalidSchedule, http.StatusBadRequest, problem.CodeInvalidSchedule},
+	{service.ErrInvalidTimeZone, http.StatusBadRequest, problem.CodeInvalidTimeZone},
+	{service.ErrInvalidRange, http.StatusBadRequest, problem.CodeInvalidDateRange},
+	{service.ErrInvalidWebhook, http.StatusBadRequest, problem.CodeInvalidWebhook},
+	{service.ErrInvalidBookingRules, http.StatusBadRequest, problem.CodeInvalidBookingRules},
+	{service.ErrInvalidPolicy, http.StatusBadRequest, problem.CodeInvalidPolicy},
+	{service.ErrInvalidCancellation, http.StatusBadRequest, problem.CodeInvalidCancellation},
+	{service.ErrInvalidModification, http.StatusBadRequest, problem.CodeInvalidModification},
+}
+
+// writeError отвечает problem+json по ошибке нижних слоёв. Неизвестные ошибки логируются
+// и отдаются клиенту как 500 без подробностей, чтобы не раскрывать SQL и ответы соседних сервисов.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			detail := m.err.Error()
+			// Для ошибок вида "Repo.Method: <sentinel>: подробности" оставляем только "<sentinel>: подробности"
+			if m.status < http.StatusInternalServerError {
+				msg := err.Error()
+				if i := strings.Index(msg, detail); i >= 0 {
+					detail = msg[i:]
+				}
+			} else {
+				log.Printf("%s %s: %v\n", r.Method, r.URL.Path, err)
+			}
+			problem.Write(w, r, m.status, m.code, detail)
+			return
+		}
+	}
+
+	log.Printf("%s %s: %v\n", r.Method, r.URL.Path, err)
+	problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "internal server error")
+}
+
+// writeProblem отвечает problem+json для ошибок, обнаруженных в самом хендлере (разбор запроса).
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	problem.Write(w, r, status, code, detail)
+}
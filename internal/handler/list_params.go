@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"booking-service/internal/model"
+	"booking-service/internal/repository"
+)
+
+// parseListFilter разбирает общие query-параметры списков броней:
+// limit, cursor, status (через запятую), listing_id, owner_id, from, to (RFC3339), sort, order (asc|desc).
+func parseListFilter(r *http.Request) (repository.ListFilter, error) {
+	q := r.URL.Query()
+	f := repository.ListFilter{
+		ListingID: q.Get("listing_id"),
+		OwnerID:   q.Get("owner_id"),
+		SortBy:    q.Get("sort"),
+		Cursor:    q.Get("cursor"),
+		Desc:      true,
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > repository.MaxPageLimit {
+			return f, errors.New("invalid 'limit' (expected 1.." + strconv.Itoa(repository.MaxPageLimit) + ")")
+		}
+		f.Limit = limit
+	}
+
+	switch strings.ToLower(q.Get("order")) {
+	case "", "desc":
+	case "asc":
+		f.Desc = false
+	default:
+		return f, errors.New("invalid 'order' (expected asc or desc)")
+	}
+
+	for _, v := range q["status"] {
+		for _, st := range strings.Split(v, ",") {
+			st = strings.ToUpper(strings.TrimSpace(st))
+			if st == "" {
+				continue
+			}
+			if !isKnownStatus(st) {
+				return f, errors.New("unknown status " + st)
+			}
+			f.Statuses = append(f.Statuses, st)
+		}
+	}
+
+	for name, dst := range map[string]**time.Time{"from": &f.From, "to": &f.To} {
+		v := q.Get(name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, errors.New("invalid '" + name + "' format (RFC3339 expected)")
+		}
+		*dst = &t
+	}
+	return f, nil
+}
+
+func isKnownStatus(s string) bool {
+	switch s {
+	case model.StatusPendingPayment, model.StatusPending, model.StatusConfirmed, model.StatusRejected,
+		model.StatusCancelled, model.StatusCompleted, model.StatusExpired:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"booking-service/internal/repository"
+)
+
+func TestParseListFilter(t *testing.T) {
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 6, 8, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name  string
+		query string
+		want  repository.ListFilter
+	}{
+		{"defaults", "", repository.ListFilter{Desc: true}},
+		{"limit lower bound", "limit=1", repository.ListFilter{Desc: true, Limit: 1}},
+		{"limit upper bound", "limit=200", repository.ListFilter{Desc: true, Limit: repository.MaxPageLimit}},
+		{"ascending", "order=ASC&sort=created_at", repository.ListFilter{SortBy: "created_at"}},
+		{
+			"statuses in one and several params", "status=pending,%20confirmed&status=CANCELLED,",
+			repository.ListFilter{Desc: true, Statuses: []string{"PENDING", "CONFIRMED", "CANCELLED"}},
+		},
+		{
+			"ids, window and cursor", "listing_id=l-1&owner_id=o-1&from=2025-06-01T00:00:00Z&to=2025-06-08T00:00:00Z&cursor=abc",
+			repository.ListFilter{ListingID: "l-1", OwnerID: "o-1", From: &from, To: &to, Cursor: "abc", Desc: true},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/bookings?"+c.query, nil)
+			got, err := parseListFilter(r)
+			if err != nil {
+				t.Fatalf("parseListFilter: %v", err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("filter = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseListFilterRejects(t *testing.T) {
+	for _, query := range []string{
+		"limit=0",
+		"limit=201",
+		"limit=-5",
+		"limit=ten",
+		"order=sideways",
+		"status=UNKNOWN",
+		"from=2025-06-01",
+		"to=tomorrow",
+	} {
+		t.Run(query, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/bookings?"+query, nil)
+			if _, err := parseListFilter(r); err == nil {
+				t.Fatalf("parseListFilter(%q) accepted invalid input", query)
+			}
+		})
+	}
+}
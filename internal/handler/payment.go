@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"booking-service/internal/problem"
+	"booking-service/internal/service"
+)
+
+const maxWebhookBody = 1 << 20
+
+type PaymentHandler struct {
+	svc *service.PaymentService
+}
+
+func NewPaymentHandler(svc *service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{svc: svc}
+}
+
+// RegisterRoutes регистрирует маршруты для пользователей (внутри группы с JWT).
+func (h *PaymentHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/bookings/{bookingID}/payment", h.getPayment) // GET    /bookings/{bookingID}/payment
+}
+
+// RegisterWebhookRoutes регистрирует приём уведомлений провайдера. Они приходят без JWT —
+// подлинность проверяется подписью провайдера.
+func (h *PaymentHandler) RegisterWebhookRoutes(r chi.Router) {
+	r.Post("/payments/webhook", h.webhook) // POST   /payments/webhook
+}
+
+// getPayment обрабатывает GET /bookings/{bookingID}/payment
+func (h *PaymentHandler) getPayment(w http.ResponseWriter, r *http.Request) {
+	bookingID, ok := uuidParam(w, r, "bookingID")
+	if !ok {
+		return
+	}
+
+	p, err := h.svc.GetPayment(r.Context(), bookingID, actorFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// webhook обрабатывает POST /payments/webhook
+func (h *PaymentHandler) webhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Cannot read request body")
+		return
+	}
+	if err := h.svc.HandleWebhook(r.Context(), r.Header, body); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
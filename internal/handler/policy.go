@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"booking-service/internal/middleware"
+	"booking-service/internal/model"
+	"booking-service/internal/problem"
+	"booking-service/internal/service"
+)
+
+type PolicyHandler struct {
+	svc *service.PolicyService
+}
+
+func NewPolicyHandler(svc *service.PolicyService) *PolicyHandler {
+	return &PolicyHandler{svc: svc}
+}
+
+func (h *PolicyHandler) RegisterRoutes(r chi.Router) {
+	ownerOrAdmin := middleware.RequireRole(middleware.RoleOwner, middleware.RoleAdmin)
+
+	r.Get("/listings/{listingID}/cancellation-policy", h.getCancellationPolicy)
+	r.With(ownerOrAdmin).Put("/listings/{listingID}/cancellation-policy", h.putCancellationPolicy)
+	r.With(ownerOrAdmin).Delete("/listings/{listingID}/cancellation-policy", h.deleteCancellationPolicy)
+	r.Get("/listings/{listingID}/booking-rules", h.getBookingRules)
+	r.With(ownerOrAdmin).Put("/listings/{listingID}/booking-rules", h.putBookingRules)
+	r.With(ownerOrAdmin).Delete("/listings/{listingID}/booking-rules", h.deleteBookingRules)
+}
+
+// getCancellationPolicy обрабатывает GET /listings/{listingID}/cancellation-policy
+func (h *PolicyHandler) getCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	p, err := h.svc.GetCancellationPolicy(r.Context(), chi.URLParam(r, "listingID"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// putCancellationPolicy обрабатывает PUT /listings/{listingID}/cancellation-policy
+func (h *PolicyHandler) putCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	var p model.CancellationPolicy
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid JSON body")
+		return
+	}
+	p.ListingID = chi.URLParam(r, "listingID")
+
+	if err := h.svc.SaveCancellationPolicy(r.Context(), &p, actorFromRequest(r)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// deleteCancellationPolicy обрабатывает DELETE /listings/{listingID}/cancellation-policy
+func (h *PolicyHandler) deleteCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.DeleteCancellationPolicy(r.Context(), chi.URLParam(r, "listingID"), actorFromRequest(r)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getBookingRules обрабатывает GET /listings/{listingID}/booking-rules
+func (h *PolicyHandler) getBookingRules(w http.ResponseWriter, r *http.Request) {
+	br, err := h.svc.GetBookingRules(r.Context(), chi.URLParam(r, "listingID"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(br)
+}
+
+// putBookingRules обрабатывает PUT /listings/{listingID}/booking-rules
+func (h *PolicyHandler) putBookingRules(w http.ResponseWriter, r *http.Request) {
+	var br model.BookingRules
+	if err := json.NewDecoder(r.Body).Decode(&br); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid JSON body")
+		return
+	}
+	br.ListingID = chi.URLParam(r, "listingID")
+
+	if err := h.svc.SaveBookingRules(r.Context(), &br, actorFromRequest(r)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(br)
+}
+
+// deleteBookingRules обрабатывает DELETE /listings/{listingID}/booking-rules
+func (h *PolicyHandler) deleteBookingRules(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.DeleteBookingRules(r.Context(), chi.URLParam(r, "listingID"), actorFromRequest(r)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"booking-service/internal/middleware"
+	"booking-service/internal/model"
+	"booking-service/internal/problem"
+	"booking-service/internal/service"
+)
+
+type ScheduleHandler struct {
+	svc *service.ScheduleService
+}
+
+func NewScheduleHandler(svc *service.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{svc: svc}
+}
+
+func (h *ScheduleHandler) RegisterRoutes(r chi.Router) {
+	ownerOrAdmin := middleware.RequireRole(middleware.RoleOwner, middleware.RoleAdmin)
+
+	r.Get("/listings/{listingID}/schedule", h.getSchedule)
+	r.With(ownerOrAdmin).Put("/listings/{listingID}/schedule", h.putSchedule)
+	r.With(ownerOrAdmin).Delete("/listings/{listingID}/schedule", h.deleteSchedule)
+	r.Get("/listings/{listingID}/closures", h.listClosures) // ?from=YYYY-MM-DD&to=YYYY-MM-DD
+	r.With(ownerOrAdmin).Put("/listings/{listingID}/closures/{date}", h.putClosure)
+	r.With(ownerOrAdmin).Delete("/listings/{listingID}/closures/{date}", h.deleteClosure)
+}
+
+// getSchedule обрабатывает GET /listings/{listingID}/schedule
+func (h *ScheduleHandler) getSchedule(w http.ResponseWriter, r *http.Request) {
+	sch, err := h.svc.GetSchedule(r.Context(), chi.URLParam(r, "listingID"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sch)
+}
+
+// putSchedule обрабатывает PUT /listings/{listingID}/schedule
+func (h *ScheduleHandler) putSchedule(w http.ResponseWriter, r *http.Request) {
+	var sch model.ListingSchedule
+	if err := json.NewDecoder(r.Body).Decode(&sch); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid JSON body")
+		return
+	}
+	sch.ListingID = chi.URLParam(r, "listingID")
+
+	if err := h.svc.SaveSchedule(r.Context(), &sch, actorFromRequest(r)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sch)
+}
+
+// deleteSchedule обрабатывает DELETE /listings/{listingID}/schedule
+func (h *ScheduleHandler) deleteSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.DeleteSchedule(r.Context(), chi.URLParam(r, "listingID"), actorFromRequest(r)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listClosures обрабатывает GET /listings/{listingID}/closures?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *ScheduleHandler) listClosures(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if from == "" || to == "" {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Missing 'from' or 'to' query parameter")
+		return
+	}
+
+	list, err := h.svc.ListClosures(r.Context(), chi.URLParam(r, "listingID"), from, to)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// putClosure обрабатывает PUT /listings/{listingID}/closures/{date}
+func (h *ScheduleHandler) putClosure(w http.ResponseWriter, r *http.Request) {
+	var c model.ListingClosure
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid JSON body")
+		return
+	}
+	c.ListingID = chi.URLParam(r, "listingID")
+	c.Date = chi.URLParam(r, "date")
+
+	if err := h.svc.SaveClosure(r.Context(), &c, actorFromRequest(r)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// deleteClosure обрабатывает DELETE /listings/{listingID}/closures/{date}
+func (h *ScheduleHandler) deleteClosure(w http.ResponseWriter, r *http.Request) {
+	err := h.svc.DeleteClosure(r.Context(), chi.URLParam(r, "listingID"), chi.URLParam(r, "date"), actorFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
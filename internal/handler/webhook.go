@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"booking-service/internal/middleware"
+	"booking-service/internal/problem"
+	"booking-service/internal/service"
+)
+
+type WebhookHandler struct {
+	svc *service.WebhookService
+}
+
+func NewWebhookHandler(svc *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{svc: svc}
+}
+
+func (h *WebhookHandler) RegisterRoutes(r chi.Router) {
+	ownerOrAdmin := middleware.RequireRole(middleware.RoleOwner, middleware.RoleAdmin)
+
+	r.With(ownerOrAdmin).Route("/webhooks", func(r chi.Router) {
+		r.Post("/subscriptions", h.createSubscription)                        // POST   /webhooks/subscriptions
+		r.Get("/subscriptions", h.listSubscriptions)                          // GET    /webhooks/subscriptions?owner_id=...
+		r.Get("/subscriptions/{subscriptionID}", h.getSubscription)           // GET    /webhooks/subscriptions/{id}
+		r.Put("/subscriptions/{subscriptionID}", h.updateSubscription)        // PUT    /webhooks/subscriptions/{id}
+		r.Delete("/subscriptions/{subscriptionID}", h.deleteSubscription)     // DELETE /webhooks/subscriptions/{id}
+		r.Get("/subscriptions/{subscriptionID}/deliveries", h.listDeliveries) // GET    /webhooks/subscriptions/{id}/deliveries
+		r.Get("/deliveries/{deliveryID}", h.getDelivery)                      // GET    /webhooks/deliveries/{id}
+		r.Post("/deliveries/{deliveryID}/redeliver", h.redeliver)             // POST   /webhooks/deliveries/{id}/redeliver
+	})
+}
+
+// createSubscription обрабатывает POST /webhooks/subscriptions
+func (h *WebhookHandler) createSubscription(w http.ResponseWriter, r *http.Request) {
+	var req service.WebhookSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid JSON body")
+		return
+	}
+
+	sub, err := h.svc.CreateSubscription(r.Context(), actorFromRequest(r), req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sub)
+}
+
+// listSubscriptions обрабатывает GET /webhooks/subscriptions. По умолчанию — подписки вызывающего.
+func (h *WebhookHandler) listSubscriptions(w http.ResponseWriter, r *http.Request) {
+	actor := actorFromRequest(r)
+	ownerID := r.URL.Query().Get("owner_id")
+	if ownerID == "" {
+		ownerID = actor.UserID
+	}
+
+	list, err := h.svc.ListSubscriptions(r.Context(), ownerID, actor)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// getSubscription обрабатывает GET /webhooks/subscriptions/{subscriptionID}
+func (h *WebhookHandler) getSubscription(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidParam(w, r, "subscriptionID")
+	if !ok {
+		return
+	}
+
+	sub, err := h.svc.GetSubscription(r.Context(), id, actorFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sub)
+}
+
+// updateSubscription обрабатывает PUT /webhooks/subscriptions/{subscriptionID}
+func (h *WebhookHandler) updateSubscription(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidParam(w, r, "subscriptionID")
+	if !ok {
+		return
+	}
+	var req service.WebhookSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid JSON body")
+		return
+	}
+
+	sub, err := h.svc.UpdateSubscription(r.Context(), id, actorFromRequest(r), req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sub)
+}
+
+// deleteSubscription обрабатывает DELETE /webhooks/subscriptions/{subscriptionID}
+func (h *WebhookHandler) deleteSubscription(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidParam(w, r, "subscriptionID")
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteSubscription(r.Context(), id, actorFromRequest(r)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listDeliveries обрабатывает GET /webhooks/subscriptions/{subscriptionID}/deliveries
+func (h *WebhookHandler) listDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidParam(w, r, "subscriptionID")
+	if !ok {
+		return
+	}
+
+	list, err := h.svc.ListDeliveries(r.Context(), id, actorFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// getDelivery обрабатывает GET /webhooks/deliveries/{deliveryID}
+func (h *WebhookHandler) getDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidParam(w, r, "deliveryID")
+	if !ok {
+		return
+	}
+
+	d, err := h.svc.GetDelivery(r.Context(), id, actorFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d)
+}
+
+// redeliver обрабатывает POST /webhooks/deliveries/{deliveryID}/redeliver
+func (h *WebhookHandler) redeliver(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidParam(w, r, "deliveryID")
+	if !ok {
+		return
+	}
+
+	d, err := h.svc.Redeliver(r.Context(), id, actorFromRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(d)
+}
+
+// uuidParam читает UUID из параметра пути name; при ошибке сам отвечает 400.
+func uuidParam(w http.ResponseWriter, r *http.Request, name string) (string, bool) {
+	v := chi.URLParam(r, name)
+	if _, err := uuid.Parse(v); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Invalid "+name)
+		return "", false
+	}
+	return v, true
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+
+	"booking-service/internal/problem"
+)
+
+// Роли пользователей, которые выдаёт user-service в JWT.
+const (
+	RoleGuest = "guest"
+	RoleOwner = "owner"
+	RoleAdmin = "admin"
+)
+
+// RequireRole пропускает запрос дальше, только если у Principal есть хотя бы одна из ролей roles.
+// Должен стоять после JWTAuthMiddleware. Используется как политика отдельного маршрута:
+//
+//	r.With(middleware.RequireRole(middleware.RoleAdmin)).Get("/", h.listAllBookings)
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Missing caller identity")
+				return
+			}
+			for _, role := range roles {
+				if principal.HasRole(role) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			problem.Write(w, r, http.StatusForbidden, problem.CodeForbidden, "Insufficient role for this operation")
+		})
+	}
+}
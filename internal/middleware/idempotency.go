@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"booking-service/internal/model"
+	"booking-service/internal/problem"
+)
+
+// IdempotencyKeyHeader — заголовок, которым клиент помечает повторяемый POST-запрос.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const maxIdempotencyKeyLen = 255
+
+// maxIdempotentBodyBytes — предел тела запроса: тело целиком читается в память, чтобы посчитать хэш.
+const maxIdempotentBodyBytes = 1 << 20
+
+// IdempotencyStore хранит ключи идемпотентности и сохранённые ответы.
+// Реализуется repository.IdempotencyRepository.
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, userID, key, requestHash string, ttl time.Duration) (*model.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, userID, key string, status int, contentType string, body []byte) error
+	Release(ctx context.Context, userID, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// Idempotency делает маршрут идемпотентным по заголовку Idempotency-Key. Ключ действует
+// в пределах пользователя ttl времени:
+//   - первый запрос выполняется, ответ (кроме 5xx) сохраняется;
+//   - повтор с тем же телом получает сохранённый ответ и заголовок Idempotent-Replayed: true;
+//   - повтор с другим телом — 422, повтор до завершения первого запроса — 409.
+//
+// Тело запроса с ключом ограничено 1 МиБ (больше — 413). Запросы без заголовка проходят как обычно.
+// Должен стоять после JWTAuthMiddleware.
+func Idempotency(store IdempotencyStore, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLen {
+				problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Idempotency-Key is too long")
+				return
+			}
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Missing caller identity")
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodyBytes))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				problem.Write(w, r, http.StatusRequestEntityTooLarge, problem.CodeRequestTooLarge, "Request body is too large")
+				return
+			}
+			if err != nil {
+				problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Cannot read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// Ключ привязан к конкретной операции: метод и путь входят в хэш вместе с телом
+			sum := sha256.New()
+			sum.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+			sum.Write(body)
+			hash := hex.EncodeToString(sum.Sum(nil))
+
+			rec, created, err := store.Reserve(r.Context(), principal.UserID, key, hash, ttl)
+			if err != nil {
+				log.Printf("%s %s: %v\n", r.Method, r.URL.Path, err)
+				problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "internal server error")
+				return
+			}
+			if !created {
+				replay(w, r, rec, hash)
+				return
+			}
+
+			rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rw, r)
+
+			// Запись ответа не должна зависеть от отмены запроса клиентом
+			ctx := context.WithoutCancel(r.Context())
+			if rw.status >= http.StatusInternalServerError {
+				err = store.Release(ctx, principal.UserID, key)
+			} else {
+				err = store.Complete(ctx, principal.UserID, key, rw.status, rw.Header().Get("Content-Type"), rw.body.Bytes())
+			}
+			if err != nil {
+				log.Printf("%s %s: saving idempotent response: %v\n", r.Method, r.URL.Path, err)
+			}
+		})
+	}
+}
+
+// replay отвечает на повтор запроса с уже занятым ключом.
+func replay(w http.ResponseWriter, r *http.Request, rec *model.IdempotencyRecord, hash string) {
+	switch {
+	case rec.RequestHash != hash:
+		problem.Write(w, r, http.StatusUnprocessableEntity, problem.CodeIdempotencyKeyReused,
+			"Idempotency-Key was already used with a different request")
+	case rec.StatusCode == nil:
+		problem.Write(w, r, http.StatusConflict, problem.CodeIdempotencyInProgress,
+			"A request with this Idempotency-Key is still in progress")
+	default:
+		if rec.ContentType != nil && *rec.ContentType != "" {
+			w.Header().Set("Content-Type", *rec.ContentType)
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(*rec.StatusCode)
+		w.Write(rec.ResponseBody)
+	}
+}
+
+// SweepIdempotencyKeys каждые interval удаляет истёкшие ключи, пока не отменён ctx.
+func SweepIdempotencyKeys(ctx context.Context, store IdempotencyStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := store.DeleteExpired(ctx)
+			if err != nil {
+				log.Printf("idempotency sweeper: %v\n", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("idempotency sweeper: removed %d expired keys\n", n)
+			}
+		}
+	}
+}
+
+// recordingWriter пропускает ответ клиенту и параллельно запоминает статус и тело.
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status = status
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
@@ -0,0 +1,236 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"booking-service/internal/model"
+	"booking-service/internal/problem"
+)
+
+// memoryIdempotencyStore — IdempotencyStore в памяти с той же семантикой, что у репозитория:
+// Reserve создаёт запись без ответа или возвращает уже существующую.
+type memoryIdempotencyStore struct {
+	mu       sync.Mutex
+	records  map[string]*model.IdempotencyRecord
+	released []string
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: map[string]*model.IdempotencyRecord{}}
+}
+
+func (s *memoryIdempotencyStore) Reserve(_ context.Context, userID, key, requestHash string, ttl time.Duration) (*model.IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[userID+"/"+key]; ok {
+		cp := *rec
+		return &cp, false, nil
+	}
+	rec := &model.IdempotencyRecord{UserID: userID, Key: key, RequestHash: requestHash, ExpiresAt: time.Now().Add(ttl)}
+	s.records[userID+"/"+key] = rec
+	cp := *rec
+	return &cp, true, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(_ context.Context, userID, key string, status int, contentType string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := s.records[userID+"/"+key]
+	rec.StatusCode, rec.ContentType, rec.ResponseBody = &status, &contentType, body
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, userID, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, userID+"/"+key)
+	s.released = append(s.released, key)
+	return nil
+}
+
+func (s *memoryIdempotencyStore) DeleteExpired(context.Context) (int64, error) { return 0, nil }
+
+// countingHandler отвечает 201 с номером вызова в теле.
+type countingHandler struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.calls++
+	n := h.calls
+	h.mu.Unlock()
+	io.Copy(io.Discard, r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int{"call": n})
+}
+
+func idempotentRequest(user, key, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(IdempotencyKeyHeader, key)
+	}
+	if user != "" {
+		r = r.WithContext(WithPrincipal(r.Context(), Principal{UserID: user}))
+	}
+	return r
+}
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func problemCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var p struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("decode problem %q: %v", w.Body.String(), err)
+	}
+	return p.Code
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	next := &countingHandler{}
+	h := Idempotency(store, time.Hour)(next)
+
+	first := serve(h, idempotentRequest("u-1", "k-1", `{"listing_id":"l-1"}`))
+	if first.Code != http.StatusCreated || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first response: %d, replayed=%q", first.Code, first.Header().Get("Idempotent-Replayed"))
+	}
+
+	second := serve(h, idempotentRequest("u-1", "k-1", `{"listing_id":"l-1"}`))
+	if second.Code != http.StatusCreated || second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("replay: %d, replayed=%q", second.Code, second.Header().Get("Idempotent-Replayed"))
+	}
+	if second.Body.String() != first.Body.String() || second.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("replayed %q (%s), want %q (application/json)",
+			second.Body.String(), second.Header().Get("Content-Type"), first.Body.String())
+	}
+
+	// Тот же ключ у другого пользователя — отдельный запрос
+	if w := serve(h, idempotentRequest("u-2", "k-1", `{"listing_id":"l-1"}`)); w.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatal("key of another user was replayed")
+	}
+	// Без ключа запрос выполняется каждый раз
+	serve(h, idempotentRequest("u-1", "", `{"listing_id":"l-1"}`))
+	if next.calls != 3 {
+		t.Fatalf("handler called %d times, want 3", next.calls)
+	}
+}
+
+func TestIdempotencyRejectsOtherRequest(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	next := &countingHandler{}
+	h := Idempotency(store, time.Hour)(next)
+
+	serve(h, idempotentRequest("u-1", "k-1", `{"listing_id":"l-1"}`))
+	w := serve(h, idempotentRequest("u-1", "k-1", `{"listing_id":"l-2"}`))
+	if w.Code != http.StatusUnprocessableEntity || problemCode(t, w) != problem.CodeIdempotencyKeyReused {
+		t.Fatalf("other body: %d %s", w.Code, w.Body.String())
+	}
+
+	// Тот же ключ и тело на другой путь — тоже другой запрос
+	r := idempotentRequest("u-1", "k-1", `{"listing_id":"l-1"}`)
+	r.URL.Path = "/bookings/quote"
+	if w := serve(h, r); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("other path: %d, want 422", w.Code)
+	}
+	if next.calls != 1 {
+		t.Fatalf("handler called %d times, want 1", next.calls)
+	}
+}
+
+func TestIdempotencyConflictWhileInProgress(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	entered, release := make(chan struct{}), make(chan struct{})
+	h := Idempotency(store, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- serve(h, idempotentRequest("u-1", "k-1", `{}`)) }()
+	<-entered
+
+	w := serve(h, idempotentRequest("u-1", "k-1", `{}`))
+	if w.Code != http.StatusConflict || problemCode(t, w) != problem.CodeIdempotencyInProgress {
+		t.Fatalf("concurrent retry: %d %s", w.Code, w.Body.String())
+	}
+
+	close(release)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Fatalf("first request: %d, want 201", first.Code)
+	}
+	if w := serve(h, idempotentRequest("u-1", "k-1", `{}`)); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry after completion: %d, replayed=%q", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+}
+
+func TestIdempotencyBodyTooLarge(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	next := &countingHandler{}
+	h := Idempotency(store, time.Hour)(next)
+
+	w := serve(h, idempotentRequest("u-1", "k-1", strings.Repeat("x", maxIdempotentBodyBytes+1)))
+	if w.Code != http.StatusRequestEntityTooLarge || problemCode(t, w) != problem.CodeRequestTooLarge {
+		t.Fatalf("large body: %d %s", w.Code, w.Body.String())
+	}
+	if next.calls != 0 || len(store.records) != 0 {
+		t.Fatalf("large body reached the handler (%d calls) or reserved a key (%d)", next.calls, len(store.records))
+	}
+
+	if w := serve(h, idempotentRequest("u-1", "k-1", strings.Repeat("x", maxIdempotentBodyBytes))); w.Code != http.StatusCreated {
+		t.Fatalf("body at the limit: %d, want 201", w.Code)
+	}
+}
+
+func TestIdempotencyReleasesKeyOnServerError(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	fail := true
+	h := Idempotency(store, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	if w := serve(h, idempotentRequest("u-1", "k-1", `{}`)); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("first request: %d, want 503", w.Code)
+	}
+	if len(store.released) != 1 || len(store.records) != 0 {
+		t.Fatalf("key was not released after 5xx: released=%v records=%d", store.released, len(store.records))
+	}
+
+	fail = false
+	w := serve(h, idempotentRequest("u-1", "k-1", `{}`))
+	if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("retry after 5xx: %d, replayed=%q; want a fresh 201", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+}
+
+func TestIdempotencyRejectsInvalidKeyUse(t *testing.T) {
+	h := Idempotency(newMemoryIdempotencyStore(), time.Hour)(&countingHandler{})
+	if w := serve(h, idempotentRequest("", "k-1", `{}`)); w.Code != http.StatusUnauthorized {
+		t.Fatalf("no principal: %d, want 401", w.Code)
+	}
+	if w := serve(h, idempotentRequest("u-1", strings.Repeat("k", maxIdempotencyKeyLen+1), `{}`)); w.Code != http.StatusBadRequest {
+		t.Fatalf("long key: %d, want 400", w.Code)
+	}
+}
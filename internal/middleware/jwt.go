@@ -1,18 +1,19 @@
 package middleware
 
 import (
+	"booking-service/internal/audit"
+	"booking-service/internal/problem"
 	"github.com/golang-jwt/jwt/v5"
-	"log" // 👈 Добавляем логирование
+	"log"
 	"net/http"
 	"strings"
 )
 
 func JWTAuthMiddleware(next http.Handler, secret string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Println("DEBUG: JWT middleware called") // 👈 Добавь это
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			http.Error(w, "Missing or invalid Authorization header", http.StatusUnauthorized)
+			problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Missing or invalid Authorization header")
 			return
 		}
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
@@ -25,11 +26,24 @@ func JWTAuthMiddleware(next http.Handler, secret string) http.Handler {
 		})
 		if err != nil || !token.Valid {
 			log.Printf("JWT parse error: %v\n", err) // 👈 Уже было
-			http.Error(w, "Invalid token: "+err.Error(), http.StatusUnauthorized)
+			problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Invalid or expired token")
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Invalid token claims")
+			return
+		}
+		principal := principalFromClaims(claims)
+		if principal.UserID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Token has no subject")
+			return
+		}
+
+		ctx := WithPrincipal(r.Context(), principal)
+		ctx = audit.WithActor(ctx, principal.UserID) // автор изменений для журнала booking_audit
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 
 }
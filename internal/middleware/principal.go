@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Principal — вызывающий пользователь, извлечённый из проверенного JWT.
+type Principal struct {
+	UserID string
+	Roles  []string
+}
+
+// HasRole сообщает, есть ли у пользователя роль role.
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal кладёт Principal в контекст запроса.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext возвращает Principal, положенный в контекст JWTAuthMiddleware.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// principalFromClaims собирает Principal из claims токена.
+// ID пользователя берётся из "sub", а если его нет — из "user_id";
+// роли — из массива "roles" или строки "role" (по умолчанию RoleGuest).
+func principalFromClaims(claims jwt.MapClaims) Principal {
+	var p Principal
+	if sub, err := claims.GetSubject(); err == nil && sub != "" {
+		p.UserID = sub
+	} else if id, ok := claims["user_id"].(string); ok {
+		p.UserID = id
+	}
+
+	switch roles := claims["roles"].(type) {
+	case []interface{}:
+		for _, r := range roles {
+			if s, ok := r.(string); ok && s != "" {
+				p.Roles = append(p.Roles, s)
+			}
+		}
+	case string:
+		p.Roles = append(p.Roles, roles)
+	}
+	if role, ok := claims["role"].(string); ok && role != "" {
+		p.Roles = append(p.Roles, role)
+	}
+	// Токен без ролей считаем токеном обычного гостя
+	if len(p.Roles) == 0 {
+		p.Roles = []string{RoleGuest}
+	}
+	return p
+}
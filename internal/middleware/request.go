@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"booking-service/internal/audit"
+)
+
+// RequestIDHeader — заголовок с ID запроса. Пришедший от клиента или прокси ID сохраняется,
+// иначе генерируется новый; в обоих случаях он возвращается в ответе.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLen = 128
+
+// RequestContext возвращает middleware, которое кладёт в контекст запроса audit.Info с ID запроса и IP клиента.
+// X-Forwarded-For/X-Real-IP учитываются, только если соединение пришло от прокси из trustedProxies:
+// иначе любой клиент подставил бы в журнал изменений чужой адрес. Автора изменений добавляет JWTAuthMiddleware.
+func RequestContext(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if id == "" || len(id) > maxRequestIDLen {
+				id = uuid.NewString()
+			}
+			w.Header().Set(RequestIDHeader, id)
+
+			ctx := audit.WithInfo(r.Context(), audit.Info{RequestID: id, IP: clientIP(r, trustedProxies)})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// clientIP возвращает адрес клиента. Если соединение пришло не от доверенного прокси, это RemoteAddr.
+// Иначе X-Forwarded-For просматривается справа налево (каждый прокси дописывает адрес в конец)
+// и берётся первый адрес не из trusted — всё левее мог подставить сам клиент.
+// Без X-Forwarded-For используется X-Real-IP.
+func clientIP(r *http.Request, trusted []netip.Prefix) string {
+	remote, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	client := remote.Addr().Unmap()
+	if !isTrusted(client, trusted) {
+		return client.String()
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	if len(r.Header.Values("X-Forwarded-For")) == 0 {
+		hops = r.Header.Values("X-Real-IP")
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break // мусор в цепочке: дальше доверять нечему, остаётся последний доверенный адрес
+		}
+		client = ip.Unmap()
+		if !isTrusted(client, trusted) {
+			break
+		}
+	}
+	return client.String()
+}
+
+func isTrusted(ip netip.Addr, trusted []netip.Prefix) bool {
+	for _, p := range trusted {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	cases := []struct {
+		name   string
+		remote string
+		xff    []string
+		realIP string
+		want   string
+	}{
+		{"direct connection", "203.0.113.7:5000", nil, "", "203.0.113.7"},
+		{"spoofed headers from untrusted peer", "203.0.113.7:5000", []string{"198.51.100.1"}, "198.51.100.2", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.2:5000", []string{"198.51.100.1"}, "", "198.51.100.1"},
+		{"client-supplied prefix is ignored", "10.0.0.2:5000", []string{"1.2.3.4, 198.51.100.1"}, "", "198.51.100.1"},
+		{"chain of trusted proxies", "10.0.0.2:5000", []string{"1.2.3.4, 198.51.100.1, 10.0.0.3"}, "", "198.51.100.1"},
+		{"several header lines", "10.0.0.2:5000", []string{"1.2.3.4", "198.51.100.1"}, "", "198.51.100.1"},
+		{"garbage hop", "10.0.0.2:5000", []string{"198.51.100.1, not-an-ip"}, "", "10.0.0.2"},
+		{"all hops trusted", "10.0.0.2:5000", []string{"10.0.0.4"}, "", "10.0.0.4"},
+		{"X-Real-IP from trusted proxy", "10.0.0.2:5000", nil, "198.51.100.1", "198.51.100.1"},
+		{"trusted proxy without headers", "10.0.0.2:5000", nil, "", "10.0.0.2"},
+		{"IPv6 peer", "[2001:db8::1]:5000", []string{"198.51.100.1"}, "", "2001:db8::1"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = c.remote
+			for _, v := range c.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if c.realIP != "" {
+				r.Header.Set("X-Real-IP", c.realIP)
+			}
+			if got := clientIP(r, trusted); got != c.want {
+				t.Fatalf("clientIP = %s, want %s", got, c.want)
+			}
+		})
+	}
+}
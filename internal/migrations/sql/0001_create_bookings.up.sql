@@ -0,0 +1,26 @@
+-- Таблица броней, соответствует model.Booking.
+-- IF NOT EXISTS: на уже развёрнутых базах таблица создавалась вручную.
+CREATE TABLE IF NOT EXISTS bookings (
+    id         uuid        PRIMARY KEY DEFAULT gen_random_uuid(),
+    listing_id text        NOT NULL,
+    user_id    text        NOT NULL,
+    owner_id   text        NOT NULL,
+    start_time timestamptz NOT NULL,
+    end_time   timestamptz NOT NULL,
+    status     text        NOT NULL DEFAULT 'PENDING',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT bookings_time_range CHECK (end_time > start_time)
+);
+
+-- ListByUserID: WHERE user_id = $1 ORDER BY start_time DESC
+CREATE INDEX IF NOT EXISTS bookings_user_id_start_time_idx
+    ON bookings (user_id, start_time DESC);
+
+-- ListByListingAndDate, HasOverlap, IsAvailableAt: WHERE listing_id = $1 AND status = ANY(...) AND ...
+CREATE INDEX IF NOT EXISTS bookings_listing_id_start_time_idx
+    ON bookings (listing_id, start_time);
+
+-- ListAllBookings: ORDER BY created_at DESC
+CREATE INDEX IF NOT EXISTS bookings_created_at_idx
+    ON bookings (created_at DESC);
@@ -0,0 +1,14 @@
+-- Запрет пересекающихся активных броней на уровне Postgres.
+-- Две брони одного листинга в статусах PENDING/CONFIRMED не могут иметь
+-- пересекающиеся интервалы [start_time, end_time).
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_overlap;
+
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_overlap
+    EXCLUDE USING gist (
+        listing_id WITH =,
+        tstzrange(start_time, end_time, '[)') WITH &&
+    )
+    WHERE (status IN ('PENDING', 'CONFIRMED'));
@@ -0,0 +1,9 @@
+CREATE INDEX IF NOT EXISTS bookings_user_id_start_time_idx
+    ON bookings (user_id, start_time DESC);
+
+CREATE INDEX IF NOT EXISTS bookings_created_at_idx
+    ON bookings (created_at DESC);
+
+DROP INDEX IF EXISTS bookings_owner_id_start_time_id_idx;
+DROP INDEX IF EXISTS bookings_user_id_start_time_id_idx;
+DROP INDEX IF EXISTS bookings_created_at_id_idx;
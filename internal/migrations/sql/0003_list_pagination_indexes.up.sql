@@ -0,0 +1,12 @@
+-- Индексы под keyset-пагинацию списков: ORDER BY <sort>, id.
+CREATE INDEX IF NOT EXISTS bookings_owner_id_start_time_id_idx
+    ON bookings (owner_id, start_time, id);
+
+CREATE INDEX IF NOT EXISTS bookings_user_id_start_time_id_idx
+    ON bookings (user_id, start_time, id);
+
+CREATE INDEX IF NOT EXISTS bookings_created_at_id_idx
+    ON bookings (created_at, id);
+
+DROP INDEX IF EXISTS bookings_user_id_start_time_idx;
+DROP INDEX IF EXISTS bookings_created_at_idx;
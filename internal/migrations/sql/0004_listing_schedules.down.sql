@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS listing_closures;
+DROP TABLE IF EXISTS listing_schedules;
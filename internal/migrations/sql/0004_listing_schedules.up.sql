@@ -0,0 +1,23 @@
+-- Расписание работы листинга: шаг слотов и недельные часы работы.
+CREATE TABLE IF NOT EXISTS listing_schedules (
+    listing_id   text        PRIMARY KEY,
+    slot_minutes integer     NOT NULL DEFAULT 60,
+    weekly_hours jsonb       NOT NULL DEFAULT '[]',
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    updated_at   timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT listing_schedules_slot_minutes CHECK (slot_minutes BETWEEN 5 AND 1440)
+);
+
+-- Закрытые (BLACKOUT) и праздничные (HOLIDAY) дни листинга.
+-- У HOLIDAY могут быть особые часы open_time/close_time, иначе день закрыт.
+CREATE TABLE IF NOT EXISTS listing_closures (
+    listing_id text        NOT NULL,
+    date       date        NOT NULL,
+    kind       text        NOT NULL,
+    reason     text        NOT NULL DEFAULT '',
+    open_time  text,
+    close_time text,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (listing_id, date),
+    CONSTRAINT listing_closures_kind CHECK (kind IN ('BLACKOUT', 'HOLIDAY'))
+);
@@ -0,0 +1 @@
+ALTER TABLE listing_schedules DROP COLUMN IF EXISTS time_zone;
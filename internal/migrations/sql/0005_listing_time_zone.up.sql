@@ -0,0 +1,3 @@
+-- IANA-часовой пояс листинга: в нём считаются часы работы и границы дней.
+ALTER TABLE listing_schedules
+    ADD COLUMN IF NOT EXISTS time_zone text NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,16 @@
+-- Ответы на POST-запросы с заголовком Idempotency-Key. Ключ уникален в пределах пользователя.
+-- status_code IS NULL — запрос ещё выполняется.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id       text        NOT NULL,
+    key           text        NOT NULL,
+    request_hash  text        NOT NULL,
+    status_code   integer,
+    response_body bytea,
+    content_type  text,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    expires_at    timestamptz NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx
+    ON idempotency_keys (expires_at);
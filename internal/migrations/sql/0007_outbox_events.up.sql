@@ -0,0 +1,15 @@
+-- Transactional outbox: доменные события пишутся в одной транзакции с изменением брони,
+-- а фоновый relay публикует их и проставляет published_at.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id           bigserial   PRIMARY KEY,
+    aggregate_id text        NOT NULL,
+    event_type   text        NOT NULL,
+    payload      jsonb       NOT NULL,
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    published_at timestamptz,
+    attempts     integer     NOT NULL DEFAULT 0,
+    last_error   text
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_unpublished_idx
+    ON outbox_events (id) WHERE published_at IS NULL;
@@ -0,0 +1,46 @@
+-- Подписки владельцев листингов на события броней
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id          uuid        PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id    text        NOT NULL,
+    url         text        NOT NULL,
+    event_types text[]      NOT NULL,
+    secret      text        NOT NULL,
+    active      boolean     NOT NULL DEFAULT true,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_subscriptions_owner_idx ON webhook_subscriptions (owner_id);
+
+-- Доставка одного события одной подписке. payload — готовое тело запроса, чтобы повторы были побайтно одинаковыми.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id               uuid        PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id  uuid        NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event_id         bigint      NOT NULL,
+    event_type       text        NOT NULL,
+    payload          jsonb       NOT NULL,
+    status           text        NOT NULL DEFAULT 'PENDING',
+    attempts         integer     NOT NULL DEFAULT 0,
+    next_attempt_at  timestamptz DEFAULT now(),
+    last_status_code integer,
+    last_error       text,
+    created_at       timestamptz NOT NULL DEFAULT now(),
+    updated_at       timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx
+    ON webhook_deliveries (next_attempt_at) WHERE status = 'PENDING';
+
+-- Журнал попыток доставки
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id          bigserial   PRIMARY KEY,
+    delivery_id uuid        NOT NULL REFERENCES webhook_deliveries (id) ON DELETE CASCADE,
+    status_code integer,
+    error       text,
+    duration_ms integer     NOT NULL,
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_attempts_delivery_idx
+    ON webhook_delivery_attempts (delivery_id, id);
@@ -0,0 +1,4 @@
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS price_items,
+    DROP COLUMN IF EXISTS currency,
+    DROP COLUMN IF EXISTS total_amount;
@@ -0,0 +1,5 @@
+-- Стоимость брони фиксируется при создании (в минимальных единицах валюты) вместе со строками расчёта.
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS total_amount bigint,
+    ADD COLUMN IF NOT EXISTS currency     text,
+    ADD COLUMN IF NOT EXISTS price_items  jsonb;
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS bookings_pending_payment_idx;
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_overlap;
+
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_overlap
+    EXCLUDE USING gist (
+        listing_id WITH =,
+        tstzrange(start_time, end_time, '[)') WITH &&
+    )
+    WHERE (status IN ('PENDING', 'CONFIRMED'));
+
+DROP TABLE IF EXISTS payment_intents;
@@ -0,0 +1,32 @@
+-- Платёжные намерения по броням (hold/capture у платёжного провайдера)
+CREATE TABLE IF NOT EXISTS payment_intents (
+    id                  uuid        PRIMARY KEY DEFAULT gen_random_uuid(),
+    booking_id          uuid        NOT NULL REFERENCES bookings (id) ON DELETE CASCADE,
+    provider            text        NOT NULL,
+    provider_payment_id text        NOT NULL,
+    amount              bigint      NOT NULL,
+    currency            text        NOT NULL,
+    status              text        NOT NULL,
+    client_secret       text,
+    refunded_amount     bigint      NOT NULL DEFAULT 0,
+    last_error          text,
+    created_at          timestamptz NOT NULL DEFAULT now(),
+    updated_at          timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (provider, provider_payment_id)
+);
+
+CREATE INDEX IF NOT EXISTS payment_intents_booking_idx ON payment_intents (booking_id);
+
+-- Неоплаченная бронь тоже держит слот, пока не истечёт
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_overlap;
+
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_overlap
+    EXCLUDE USING gist (
+        listing_id WITH =,
+        tstzrange(start_time, end_time, '[)') WITH &&
+    )
+    WHERE (status IN ('PENDING_PAYMENT', 'PENDING', 'CONFIRMED'));
+
+CREATE INDEX IF NOT EXISTS bookings_pending_payment_idx
+    ON bookings (created_at) WHERE status = 'PENDING_PAYMENT';
@@ -0,0 +1,7 @@
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS refund_amount,
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS cancellation_reason,
+    DROP COLUMN IF EXISTS cancelled_by;
+
+DROP TABLE IF EXISTS listing_cancellation_policies;
@@ -0,0 +1,16 @@
+-- Политика отмены листинга: пресет (FLEXIBLE/MODERATE/STRICT) или собственные ступени возврата.
+-- Листинги без записи получают политику FLEXIBLE.
+CREATE TABLE IF NOT EXISTS listing_cancellation_policies (
+    listing_id text        PRIMARY KEY,
+    kind       text        NOT NULL,
+    tiers      jsonb       NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- Кто, когда и почему отменил бронь, и сколько по политике вернули гостю.
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS cancelled_by        text,
+    ADD COLUMN IF NOT EXISTS cancellation_reason text,
+    ADD COLUMN IF NOT EXISTS cancelled_at        timestamptz,
+    ADD COLUMN IF NOT EXISTS refund_amount       bigint;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS bookings_pending_payment_idx;
+
+CREATE INDEX IF NOT EXISTS bookings_pending_payment_idx
+    ON bookings (created_at) WHERE status = 'PENDING_PAYMENT';
+
+DROP TABLE IF EXISTS booking_modifications;
@@ -0,0 +1,26 @@
+-- История изменений дат брони: каждая запись — одно успешное изменение с ценой и статусом до и после.
+CREATE TABLE IF NOT EXISTS booking_modifications (
+    id             bigserial   PRIMARY KEY,
+    booking_id     uuid        NOT NULL REFERENCES bookings (id) ON DELETE CASCADE,
+    modified_by    text        NOT NULL,
+    old_start_time timestamptz NOT NULL,
+    old_end_time   timestamptz NOT NULL,
+    new_start_time timestamptz NOT NULL,
+    new_end_time   timestamptz NOT NULL,
+    old_total      bigint,
+    new_total      bigint,
+    old_status     text        NOT NULL,
+    new_status     text        NOT NULL,
+    reason         text,
+    created_at     timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS booking_modifications_booking_idx
+    ON booking_modifications (booking_id, id);
+
+-- Изменённая бронь может снова ждать оплаты, поэтому срок оплаты отсчитывается
+-- от последнего перехода в PENDING_PAYMENT (updated_at), а не от создания брони.
+DROP INDEX IF EXISTS bookings_pending_payment_idx;
+
+CREATE INDEX IF NOT EXISTS bookings_pending_payment_idx
+    ON bookings (updated_at) WHERE status = 'PENDING_PAYMENT';
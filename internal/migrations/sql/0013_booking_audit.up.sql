@@ -0,0 +1,27 @@
+-- Журнал изменений броней: кто (actor_id), откуда (request_id, ip) и как (before/after) менял бронь.
+-- Пишется в той же транзакции, что и само изменение. Журнал только пополняется: UPDATE и DELETE запрещены.
+CREATE TABLE IF NOT EXISTS booking_audit (
+    id         bigserial   PRIMARY KEY,
+    booking_id uuid        NOT NULL,
+    action     text        NOT NULL,
+    actor_id   text,
+    request_id text,
+    ip         text,
+    before     jsonb,
+    after      jsonb       NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS booking_audit_booking_idx ON booking_audit (booking_id, id);
+
+CREATE OR REPLACE FUNCTION booking_audit_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'booking_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS booking_audit_append_only ON booking_audit;
+
+CREATE TRIGGER booking_audit_append_only
+    BEFORE UPDATE OR DELETE ON booking_audit
+    FOR EACH ROW EXECUTE FUNCTION booking_audit_append_only();
@@ -0,0 +1,6 @@
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS metadata,
+    DROP COLUMN IF EXISTS special_requests,
+    DROP COLUMN IF EXISTS pets,
+    DROP COLUMN IF EXISTS children,
+    DROP COLUMN IF EXISTS adults;
@@ -0,0 +1,8 @@
+-- Состав гостей, пожелания гостя и произвольные метаданные брони.
+-- Существующие брони считаются бронями на одного взрослого.
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS adults           integer NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS children         integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS pets             integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS special_requests text,
+    ADD COLUMN IF NOT EXISTS metadata         jsonb;
@@ -0,0 +1,14 @@
+-- Правила бронирования листинга: длительность, минимальный срок до заезда, горизонт бронирования,
+-- дни заезда и фиксированное время заезда/выезда. Листинги без записи получают правила по умолчанию.
+CREATE TABLE IF NOT EXISTS listing_booking_rules (
+    listing_id           text        PRIMARY KEY,
+    min_duration_minutes integer     NOT NULL DEFAULT 0,
+    max_duration_minutes integer     NOT NULL DEFAULT 0,
+    min_notice_minutes   integer     NOT NULL DEFAULT 0,
+    max_horizon_days     integer     NOT NULL DEFAULT 0,
+    check_in_weekdays    bigint[]    NOT NULL DEFAULT '{}',
+    check_in_time        text,
+    check_out_time       text,
+    created_at           timestamptz NOT NULL DEFAULT now(),
+    updated_at           timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,19 @@
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_overlap;
+
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_overlap
+    EXCLUDE USING gist (
+        listing_id WITH =,
+        tstzrange(start_time, end_time, '[)') WITH &&
+    )
+    WHERE (status IN ('PENDING_PAYMENT', 'PENDING', 'CONFIRMED'));
+
+DROP FUNCTION IF EXISTS booking_blocked_range(timestamptz, timestamptz, integer, integer);
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS buffer_before_minutes,
+    DROP COLUMN IF EXISTS buffer_after_minutes;
+
+ALTER TABLE listing_booking_rules
+    DROP COLUMN IF EXISTS buffer_before_minutes,
+    DROP COLUMN IF EXISTS buffer_after_minutes;
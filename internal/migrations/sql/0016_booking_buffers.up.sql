@@ -0,0 +1,38 @@
+-- Буфер (время на уборку и подготовку) до и после каждой брони листинга.
+-- Настраивается в правилах бронирования и фиксируется в брони при создании и изменении дат.
+ALTER TABLE listing_booking_rules
+    ADD COLUMN IF NOT EXISTS buffer_before_minutes integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS buffer_after_minutes  integer NOT NULL DEFAULT 0;
+
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS buffer_before_minutes integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS buffer_after_minutes  integer NOT NULL DEFAULT 0;
+
+-- Интервал, который бронь занимает вместе с буферами. Сдвиг на целое число минут не зависит
+-- от часового пояса сессии, поэтому функцию можно объявить IMMUTABLE и использовать в ограничении.
+CREATE OR REPLACE FUNCTION booking_blocked_range(
+    start_time     timestamptz,
+    end_time       timestamptz,
+    before_minutes integer,
+    after_minutes  integer
+) RETURNS tstzrange
+LANGUAGE plpgsql IMMUTABLE PARALLEL SAFE AS $$
+BEGIN
+    RETURN tstzrange(
+        start_time - make_interval(mins => before_minutes),
+        end_time + make_interval(mins => after_minutes),
+        '[)'
+    );
+END;
+$$;
+
+-- Две активные брони листинга не могут пересекаться вместе со своими буферами
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_overlap;
+
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_overlap
+    EXCLUDE USING gist (
+        listing_id WITH =,
+        booking_blocked_range(start_time, end_time, buffer_before_minutes, buffer_after_minutes) WITH &&
+    )
+    WHERE (status IN ('PENDING_PAYMENT', 'PENDING', 'CONFIRMED'));
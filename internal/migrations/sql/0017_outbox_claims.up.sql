@@ -0,0 +1,3 @@
+-- Relay забирает события короткой транзакцией и публикует их вне её: claimed_until — срок,
+-- до которого событие закреплено за relay. Если relay упал, событие подхватывается снова после этого срока.
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS claimed_until timestamptz;
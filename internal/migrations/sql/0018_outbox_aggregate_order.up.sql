@@ -0,0 +1,4 @@
+-- Relay публикует события брони по порядку и перед каждым событием ищет более ранние
+-- неопубликованные события той же брони.
+CREATE INDEX IF NOT EXISTS outbox_events_unpublished_aggregate_idx
+    ON outbox_events (aggregate_id, id) WHERE published_at IS NULL;
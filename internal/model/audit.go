@@ -0,0 +1,29 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Действия в журнале изменений брони.
+const (
+	AuditCreated       = "CREATED"
+	AuditStatusChanged = "STATUS_CHANGED"
+	AuditCancelled     = "CANCELLED"
+	AuditModified      = "MODIFIED"
+	AuditExpired       = "EXPIRED"
+)
+
+// BookingAuditEntry соответствует записи в таблице `booking_audit`.
+// Before и After — снимки брони до и после изменения (у создания Before нет).
+type BookingAuditEntry struct {
+	ID        int64            `db:"id" json:"id"`
+	BookingID string           `db:"booking_id" json:"booking_id"`
+	Action    string           `db:"action" json:"action"`
+	ActorID   *string          `db:"actor_id" json:"actor_id,omitempty"`
+	RequestID *string          `db:"request_id" json:"request_id,omitempty"`
+	IP        *string          `db:"ip" json:"ip,omitempty"`
+	Before    *json.RawMessage `db:"before" json:"before,omitempty"`
+	After     json.RawMessage  `db:"after" json:"after"`
+	CreatedAt time.Time        `db:"created_at" json:"created_at"`
+}
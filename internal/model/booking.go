@@ -1,9 +1,27 @@
 package model
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"time"
 )
 
+// Статусы брони. Платная бронь создаётся в StatusPendingPayment и переходит в StatusPending,
+// когда платёж авторизован; бесплатная сразу создаётся в StatusPending.
+const (
+	StatusPendingPayment = "PENDING_PAYMENT"
+	StatusPending        = "PENDING"
+	StatusConfirmed      = "CONFIRMED"
+	StatusRejected       = "REJECTED"
+	StatusCancelled      = "CANCELLED"
+	StatusCompleted      = "COMPLETED"
+	StatusExpired        = "EXPIRED" // не оплачена вовремя
+)
+
+// ActiveStatuses — статусы, при которых бронь занимает слот листинга.
+var ActiveStatuses = []string{StatusPendingPayment, StatusPending, StatusConfirmed}
+
 // Booking соответствует одной записи в таблице `bookings`.
 type Booking struct {
 	ID        string    `db:"id" json:"id"`
@@ -15,4 +33,52 @@ type Booking struct {
 	Status    string    `db:"status" json:"status"` // Новое поле: статус брони (NOT NULL)
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	// Состав гостей и пожелания. Вместимость листинга ограничивает Adults+Children, питомцы — отдельно.
+	Adults          int      `db:"adults" json:"adults"`
+	Children        int      `db:"children" json:"children"`
+	Pets            int      `db:"pets" json:"pets"`
+	SpecialRequests *string  `db:"special_requests" json:"special_requests,omitempty"`
+	Metadata        Metadata `db:"metadata" json:"metadata,omitempty"`
+
+	// Буфер листинга на момент создания или последнего изменения дат брони
+	Buffer
+
+	// Стоимость, зафиксированная при создании брони: последующие изменения цен листинга её не меняют.
+	// У броней, созданных до появления расчёта цен, поля пустые.
+	TotalAmount *int64     `db:"total_amount" json:"total_amount,omitempty"` // в минимальных единицах валюты
+	Currency    *string    `db:"currency" json:"currency,omitempty"`
+	PriceItems  PriceItems `db:"price_items" json:"price_items,omitempty"`
+
+	// Заполняются при отмене брони. RefundAmount — сумма к возврату по политике отмены листинга.
+	CancelledBy        *string    `db:"cancelled_by" json:"cancelled_by,omitempty"`
+	CancellationReason *string    `db:"cancellation_reason" json:"cancellation_reason,omitempty"`
+	CancelledAt        *time.Time `db:"cancelled_at" json:"cancelled_at,omitempty"`
+	RefundAmount       *int64     `db:"refund_amount" json:"refund_amount,omitempty"`
+}
+
+// Metadata — произвольные данные клиента о брони (JSON-объект), хранятся в JSONB-колонке metadata.
+type Metadata map[string]interface{}
+
+func (m Metadata) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+func (m *Metadata) Scan(src interface{}) error {
+	var raw []byte
+	switch v := src.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	case nil:
+		*m = nil
+		return nil
+	default:
+		return errors.New("Metadata: unsupported source type")
+	}
+	return json.Unmarshal(raw, m)
 }
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Виды политик отмены. Для пресетов ступени возврата фиксированы, для CUSTOM их задаёт владелец.
+const (
+	PolicyFlexible = "FLEXIBLE"
+	PolicyModerate = "MODERATE"
+	PolicyStrict   = "STRICT"
+	PolicyCustom   = "CUSTOM"
+)
+
+// RefundTier — ступень политики отмены: при отмене не позже чем за HoursBefore часов
+// до начала брони гостю возвращается Percent процентов стоимости.
+type RefundTier struct {
+	HoursBefore int `json:"hours_before"`
+	Percent     int `json:"percent"`
+}
+
+// RefundTiers — ступени политики по убыванию HoursBefore, хранятся в JSONB-колонке tiers.
+type RefundTiers []RefundTier
+
+func (t RefundTiers) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+func (t *RefundTiers) Scan(src interface{}) error {
+	var raw []byte
+	switch v := src.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	case nil:
+		*t = nil
+		return nil
+	default:
+		return errors.New("RefundTiers: unsupported source type")
+	}
+	return json.Unmarshal(raw, t)
+}
+
+// CancellationPolicy соответствует записи в таблице `listing_cancellation_policies`.
+type CancellationPolicy struct {
+	ListingID string      `db:"listing_id" json:"listing_id"`
+	Kind      string      `db:"kind" json:"kind"`
+	Tiers     RefundTiers `db:"tiers" json:"tiers"`
+	CreatedAt time.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time   `db:"updated_at" json:"updated_at"`
+}
+
+// Cancellation — данные об отмене брони, которые записываются вместе со сменой статуса.
+type Cancellation struct {
+	CancelledBy  string
+	Reason       *string
+	RefundAmount *int64
+}
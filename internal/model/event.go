@@ -0,0 +1,53 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Типы доменных событий брони.
+const (
+	EventBookingCreated   = "BookingCreated"
+	EventBookingPaid      = "BookingPaid" // платёж авторизован, бронь ждёт подтверждения владельца
+	EventBookingConfirmed = "BookingConfirmed"
+	EventBookingRejected  = "BookingRejected"
+	EventBookingCancelled = "BookingCancelled"
+	EventBookingCompleted = "BookingCompleted"
+	EventBookingExpired   = "BookingExpired"
+	EventBookingModified  = "BookingModified" // изменены даты (и, возможно, цена и статус) брони
+)
+
+// BookingEventTypes — все типы событий брони, на которые можно подписаться.
+var BookingEventTypes = []string{
+	EventBookingCreated,
+	EventBookingPaid,
+	EventBookingConfirmed,
+	EventBookingRejected,
+	EventBookingCancelled,
+	EventBookingCompleted,
+	EventBookingExpired,
+	EventBookingModified,
+}
+
+// StatusEvents сопоставляет новый статус брони событию, которое публикуется при переходе в него.
+var StatusEvents = map[string]string{
+	StatusPending:   EventBookingPaid,
+	StatusConfirmed: EventBookingConfirmed,
+	StatusRejected:  EventBookingRejected,
+	StatusCancelled: EventBookingCancelled,
+	StatusCompleted: EventBookingCompleted,
+	StatusExpired:   EventBookingExpired,
+}
+
+// OutboxEvent соответствует записи в таблице `outbox_events`.
+type OutboxEvent struct {
+	ID           int64           `db:"id"            json:"id"`
+	AggregateID  string          `db:"aggregate_id"  json:"aggregate_id"` // ID брони
+	EventType    string          `db:"event_type"    json:"type"`
+	Payload      json.RawMessage `db:"payload"       json:"data"` // снимок брони на момент события
+	CreatedAt    time.Time       `db:"created_at"    json:"occurred_at"`
+	PublishedAt  *time.Time      `db:"published_at"  json:"-"`
+	Attempts     int             `db:"attempts"      json:"-"`
+	LastError    *string         `db:"last_error"    json:"-"`
+	ClaimedUntil *time.Time      `db:"claimed_until" json:"-"` // до какого момента событие публикует один из relay
+}
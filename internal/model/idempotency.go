@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// IdempotencyRecord соответствует записи в таблице `idempotency_keys`:
+// сохранённый ответ на запрос с заголовком Idempotency-Key.
+type IdempotencyRecord struct {
+	UserID       string    `db:"user_id"`
+	Key          string    `db:"key"`
+	RequestHash  string    `db:"request_hash"`
+	StatusCode   *int      `db:"status_code"` // nil, пока исходный запрос ещё выполняется
+	ResponseBody []byte    `db:"response_body"`
+	ContentType  *string   `db:"content_type"`
+	CreatedAt    time.Time `db:"created_at"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
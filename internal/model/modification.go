@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// BookingModification соответствует записи в таблице `booking_modifications`:
+// одно изменение дат брони вместе с ценой и статусом до и после него.
+type BookingModification struct {
+	ID           int64     `db:"id" json:"id"`
+	BookingID    string    `db:"booking_id" json:"booking_id"`
+	ModifiedBy   string    `db:"modified_by" json:"modified_by"`
+	OldStartTime time.Time `db:"old_start_time" json:"old_start_time"`
+	OldEndTime   time.Time `db:"old_end_time" json:"old_end_time"`
+	NewStartTime time.Time `db:"new_start_time" json:"new_start_time"`
+	NewEndTime   time.Time `db:"new_end_time" json:"new_end_time"`
+	OldTotal     *int64    `db:"old_total" json:"old_total,omitempty"`
+	NewTotal     *int64    `db:"new_total" json:"new_total,omitempty"`
+	OldStatus    string    `db:"old_status" json:"old_status"`
+	NewStatus    string    `db:"new_status" json:"new_status"`
+	Reason       *string   `db:"reason" json:"reason,omitempty"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Статусы платёжного намерения.
+const (
+	PaymentRequiresAuthorization = "REQUIRES_AUTHORIZATION" // ждём подтверждения оплаты от провайдера (webhook)
+	PaymentAuthorized            = "AUTHORIZED"             // сумма заблокирована на карте гостя
+	PaymentCaptured              = "CAPTURED"               // списано; возвраты учитываются в RefundedAmount
+	PaymentVoided                = "VOIDED"                 // блокировка снята без списания
+	PaymentRefunded              = "REFUNDED"               // возвращено полностью
+	PaymentFailed                = "FAILED"
+)
+
+// PaymentIntent соответствует записи в таблице `payment_intents`: платёж по брони у провайдера.
+type PaymentIntent struct {
+	ID                string    `db:"id" json:"id"`
+	BookingID         string    `db:"booking_id" json:"booking_id"`
+	Provider          string    `db:"provider" json:"provider"`
+	ProviderPaymentID string    `db:"provider_payment_id" json:"provider_payment_id"`
+	Amount            int64     `db:"amount" json:"amount"` // в минимальных единицах валюты
+	Currency          string    `db:"currency" json:"currency"`
+	Status            string    `db:"status" json:"status"`
+	ClientSecret      *string   `db:"client_secret" json:"client_secret,omitempty"` // для завершения оплаты на клиенте
+	RefundedAmount    int64     `db:"refunded_amount" json:"refunded_amount"`
+	LastError         *string   `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+}
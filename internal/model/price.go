@@ -0,0 +1,50 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// Виды строк расчёта цены.
+const (
+	PriceItemLodging  = "LODGING"  // проживание по базовому/выходному/сезонному тарифу
+	PriceItemDiscount = "DISCOUNT" // скидка за длительное проживание (Amount < 0)
+	PriceItemCleaning = "CLEANING" // уборка
+	PriceItemTax      = "TAX"
+)
+
+// PriceItem — строка расчёта стоимости брони. Суммы — в минимальных единицах валюты (центах, тиынах).
+type PriceItem struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	UnitAmount  int64  `json:"unit_amount"`
+	Amount      int64  `json:"amount"`
+}
+
+// PriceItems — строки расчёта брони, хранятся в JSONB-колонке price_items.
+type PriceItems []PriceItem
+
+func (p PriceItems) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
+
+func (p *PriceItems) Scan(src interface{}) error {
+	var raw []byte
+	switch v := src.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	case nil:
+		*p = nil
+		return nil
+	default:
+		return errors.New("PriceItems: unsupported source type")
+	}
+	return json.Unmarshal(raw, p)
+}
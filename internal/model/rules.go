@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// BookingRules соответствует записи в таблице `listing_booking_rules`: ограничения на даты брони листинга.
+// Нулевые значения означают «не задано»: такое правило бронь не ограничивает (см. service.stayRules).
+type BookingRules struct {
+	ListingID          string        `db:"listing_id" json:"listing_id"`
+	MinDurationMinutes int           `db:"min_duration_minutes" json:"min_duration_minutes"`
+	MaxDurationMinutes int           `db:"max_duration_minutes" json:"max_duration_minutes"`
+	MinNoticeMinutes   int           `db:"min_notice_minutes" json:"min_notice_minutes"` // минимум времени от брони до заезда
+	MaxHorizonDays     int           `db:"max_horizon_days" json:"max_horizon_days"`     // как далеко вперёд можно бронировать
+	CheckInWeekdays    pq.Int64Array `db:"check_in_weekdays" json:"check_in_weekdays"`   // 0 — воскресенье … 6 — суббота; пусто — любой день
+	CheckInTime        *string       `db:"check_in_time" json:"check_in_time,omitempty"` // "HH:MM" по времени листинга
+	CheckOutTime       *string       `db:"check_out_time" json:"check_out_time,omitempty"`
+	CreatedAt          time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time     `db:"updated_at" json:"updated_at"`
+
+	Buffer // уборка и подготовка до и после каждой брони
+}
+
+// Buffer — время на уборку и подготовку листинга до заезда и после выезда, в минутах.
+// Задаётся в правилах бронирования листинга и фиксируется в брони: две активные брони
+// не могут пересекаться вместе со своими буферами.
+type Buffer struct {
+	BeforeMinutes int `db:"buffer_before_minutes" json:"buffer_before_minutes"`
+	AfterMinutes  int `db:"buffer_after_minutes" json:"buffer_after_minutes"`
+}
+
+// Around возвращает интервал, который [start, end) занимает вместе с буфером.
+func (b Buffer) Around(start, end time.Time) (time.Time, time.Time) {
+	return start.Add(-time.Duration(b.BeforeMinutes) * time.Minute), end.Add(time.Duration(b.AfterMinutes) * time.Minute)
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Типы закрытых дней листинга.
+const (
+	ClosureBlackout = "BLACKOUT" // листинг закрыт весь день
+	ClosureHoliday  = "HOLIDAY"  // праздник: закрыт или работает по особым часам (Open/Close)
+)
+
+// OpeningHours — рабочий интервал одного дня недели в формате "HH:MM" (Close может быть "24:00").
+// Weekday: 0 — воскресенье … 6 — суббота, как в time.Weekday.
+type OpeningHours struct {
+	Weekday time.Weekday `json:"weekday"`
+	Open    string       `json:"open"`
+	Close   string       `json:"close"`
+}
+
+// WeeklyHours — недельное расписание листинга, хранится в JSONB-колонке weekly_hours.
+// На один день может приходиться несколько интервалов (например, с перерывом на обед).
+type WeeklyHours []OpeningHours
+
+func (w WeeklyHours) Value() (driver.Value, error) {
+	if w == nil {
+		w = WeeklyHours{}
+	}
+	return json.Marshal(w)
+}
+
+func (w *WeeklyHours) Scan(src interface{}) error {
+	var raw []byte
+	switch v := src.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	case nil:
+		*w = nil
+		return nil
+	default:
+		return errors.New("WeeklyHours: unsupported source type")
+	}
+	return json.Unmarshal(raw, w)
+}
+
+// ListingSchedule соответствует записи в таблице `listing_schedules`.
+type ListingSchedule struct {
+	ListingID   string      `db:"listing_id" json:"listing_id"`
+	SlotMinutes int         `db:"slot_minutes" json:"slot_minutes"` // шаг слотов: 15, 30, 60, … 1440
+	WeeklyHours WeeklyHours `db:"weekly_hours" json:"weekly_hours"`
+	TimeZone    string      `db:"time_zone" json:"time_zone"` // IANA, например "Asia/Almaty"
+	CreatedAt   time.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time   `db:"updated_at" json:"updated_at"`
+}
+
+// ListingClosure соответствует записи в таблице `listing_closures`: закрытый или особый день листинга.
+type ListingClosure struct {
+	ListingID string    `db:"listing_id" json:"listing_id"`
+	Date      string    `db:"date" json:"date"` // YYYY-MM-DD
+	Kind      string    `db:"kind" json:"kind"`
+	Reason    string    `db:"reason" json:"reason"`
+	Open      *string   `db:"open_time" json:"open,omitempty"` // только для HOLIDAY с особыми часами
+	Close     *string   `db:"close_time" json:"close,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
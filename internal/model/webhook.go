@@ -0,0 +1,62 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Статусы доставки вебхука.
+const (
+	DeliveryPending   = "PENDING"
+	DeliverySucceeded = "SUCCEEDED"
+	DeliveryFailed    = "FAILED" // попытки исчерпаны; можно повторить вручную
+)
+
+// WebhookSubscription соответствует записи в таблице `webhook_subscriptions`.
+type WebhookSubscription struct {
+	ID         string         `db:"id"          json:"id"`
+	OwnerID    string         `db:"owner_id"    json:"owner_id"`
+	URL        string         `db:"url"         json:"url"`
+	EventTypes pq.StringArray `db:"event_types" json:"event_types"`
+	Secret     string         `db:"secret"      json:"secret,omitempty"` // отдаётся клиенту только при создании
+	Active     bool           `db:"active"      json:"active"`
+	CreatedAt  time.Time      `db:"created_at"  json:"created_at"`
+	UpdatedAt  time.Time      `db:"updated_at"  json:"updated_at"`
+}
+
+// WebhookDelivery соответствует записи в таблице `webhook_deliveries`.
+type WebhookDelivery struct {
+	ID             string          `db:"id"               json:"id"`
+	SubscriptionID string          `db:"subscription_id"  json:"subscription_id"`
+	EventID        int64           `db:"event_id"         json:"event_id"`
+	EventType      string          `db:"event_type"       json:"event_type"`
+	Payload        json.RawMessage `db:"payload"          json:"payload"`
+	Status         string          `db:"status"           json:"status"`
+	Attempts       int             `db:"attempts"         json:"attempts"`
+	NextAttemptAt  *time.Time      `db:"next_attempt_at"  json:"next_attempt_at,omitempty"`
+	LastStatusCode *int            `db:"last_status_code" json:"last_status_code,omitempty"`
+	LastError      *string         `db:"last_error"       json:"last_error,omitempty"`
+	CreatedAt      time.Time       `db:"created_at"       json:"created_at"`
+	UpdatedAt      time.Time       `db:"updated_at"       json:"updated_at"`
+
+	AttemptLog []WebhookDeliveryAttempt `db:"-" json:"attempt_log,omitempty"`
+}
+
+// WebhookDeliveryAttempt — одна попытка доставки (таблица `webhook_delivery_attempts`).
+type WebhookDeliveryAttempt struct {
+	ID         int64     `db:"id"          json:"id"`
+	DeliveryID string    `db:"delivery_id" json:"-"`
+	StatusCode *int      `db:"status_code" json:"status_code,omitempty"`
+	Error      *string   `db:"error"       json:"error,omitempty"`
+	DurationMS int       `db:"duration_ms" json:"duration_ms"`
+	CreatedAt  time.Time `db:"created_at"  json:"created_at"`
+}
+
+// DueDelivery — доставка, готовая к отправке, вместе с адресом и секретом подписки.
+type DueDelivery struct {
+	WebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
@@ -0,0 +1,144 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// FakeSignatureHeader — заголовок с подписью webhook фейкового провайдера: hex(HMAC-SHA256(secret, body)).
+const FakeSignatureHeader = "X-Fake-Signature"
+
+// FakeProvider — платёжный провайдер в памяти процесса для локальной разработки и тестов.
+// С autoAuthorize платёж авторизуется сразу, иначе ждёт webhook вида
+// {"type": "payment.authorized", "payment_id": "..."} с подписью в X-Fake-Signature.
+type FakeProvider struct {
+	secret        string
+	autoAuthorize bool
+
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+	byKey    map[string]string // idempotency key → payment ID
+}
+
+type fakePayment struct {
+	amount   int64
+	status   string // authorized, captured, voided
+	captured int64
+	refunded int64
+}
+
+func NewFakeProvider(webhookSecret string, autoAuthorize bool) *FakeProvider {
+	return &FakeProvider{
+		secret:        webhookSecret,
+		autoAuthorize: autoAuthorize,
+		payments:      map[string]*fakePayment{},
+		byKey:         map[string]string{},
+	}
+}
+
+func (p *FakeProvider) Name() string { return "fake" }
+
+func (p *FakeProvider) Authorize(_ context.Context, req AuthorizeRequest) (*Payment, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrDeclined)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := StatusRequiresAuthorization
+	if p.autoAuthorize {
+		status = StatusAuthorized
+	}
+	id, ok := p.byKey[req.IdempotencyKey]
+	if !ok {
+		id = "fake_pay_" + uuid.NewString()
+		p.payments[id] = &fakePayment{amount: req.Amount, status: status}
+		if req.IdempotencyKey != "" {
+			p.byKey[req.IdempotencyKey] = id
+		}
+	}
+	return &Payment{ID: id, Status: p.payments[id].status, ClientSecret: id + "_secret"}, nil
+}
+
+func (p *FakeProvider) Capture(_ context.Context, paymentID string, amount int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pay, ok := p.payments[paymentID]
+	if !ok {
+		return ErrUnknownPayment
+	}
+	if pay.status != StatusAuthorized || amount > pay.amount {
+		return fmt.Errorf("%w: cannot capture %d from %s payment", ErrDeclined, amount, pay.status)
+	}
+	pay.status, pay.captured = "captured", amount
+	return nil
+}
+
+func (p *FakeProvider) Void(_ context.Context, paymentID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pay, ok := p.payments[paymentID]
+	if !ok {
+		return ErrUnknownPayment
+	}
+	if pay.status == "captured" {
+		return fmt.Errorf("%w: payment already captured", ErrDeclined)
+	}
+	pay.status = "voided"
+	return nil
+}
+
+func (p *FakeProvider) Refund(_ context.Context, paymentID string, amount int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pay, ok := p.payments[paymentID]
+	if !ok {
+		return ErrUnknownPayment
+	}
+	if pay.status != "captured" || pay.refunded+amount > pay.captured {
+		return fmt.Errorf("%w: cannot refund %d", ErrDeclined, amount)
+	}
+	pay.refunded += amount
+	return nil
+}
+
+func (p *FakeProvider) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	if p.secret == "" {
+		return nil, fmt.Errorf("%w: webhook secret is not configured", ErrInvalidWebhook)
+	}
+	mac := hmac.New(sha256.New, []byte(p.secret))
+	mac.Write(body)
+	want := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(want), []byte(header.Get(FakeSignatureHeader))) {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidWebhook)
+	}
+
+	var msg struct {
+		Type      string `json:"type"`
+		PaymentID string `json:"payment_id"`
+		Reason    string `json:"reason"`
+	}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+	if msg.Type != EventAuthorized && msg.Type != EventFailed {
+		return nil, fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, msg.Type)
+	}
+
+	// Подтверждение из webhook отражаем и во внутреннем состоянии, чтобы потом прошёл Capture
+	p.mu.Lock()
+	if pay, ok := p.payments[msg.PaymentID]; ok && msg.Type == EventAuthorized && pay.status == StatusRequiresAuthorization {
+		pay.status = StatusAuthorized
+	}
+	p.mu.Unlock()
+
+	return &Event{Type: msg.Type, PaymentID: msg.PaymentID, Reason: msg.Reason}, nil
+}
@@ -0,0 +1,68 @@
+// Package payments описывает платёжного провайдера, через которого бронь оплачивается
+// по схеме hold/capture: сумма блокируется при бронировании и списывается при подтверждении владельцем.
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var (
+	// ErrDeclined — провайдер отказал в оплате (недостаточно средств, карта отклонена и т.п.).
+	ErrDeclined = errors.New("payment declined")
+	// ErrInvalidWebhook — webhook провайдера не прошёл проверку подписи или не разобран.
+	ErrInvalidWebhook = errors.New("invalid payment webhook")
+	// ErrUnknownPayment — провайдер не знает платежа с таким ID.
+	ErrUnknownPayment = errors.New("unknown payment")
+)
+
+// Статусы платежа у провайдера после Authorize.
+const (
+	StatusRequiresAuthorization = "requires_authorization" // гость ещё должен подтвердить оплату; результат придёт webhook'ом
+	StatusAuthorized            = "authorized"
+)
+
+// Типы событий из webhook провайдера.
+const (
+	EventAuthorized = "payment.authorized"
+	EventFailed     = "payment.failed"
+)
+
+// AuthorizeRequest — запрос на блокировку суммы.
+type AuthorizeRequest struct {
+	BookingID      string
+	Amount         int64 // в минимальных единицах валюты
+	Currency       string
+	IdempotencyKey string // повтор с тем же ключом не создаёт второй платёж
+}
+
+// Payment — платёж у провайдера.
+type Payment struct {
+	ID           string
+	Status       string
+	ClientSecret string
+}
+
+// Event — асинхронное уведомление провайдера о платеже.
+type Event struct {
+	Type      string
+	PaymentID string
+	Reason    string // причина отказа для EventFailed
+}
+
+// PaymentProvider — платёжный провайдер.
+type PaymentProvider interface {
+	// Name — идентификатор провайдера, сохраняется вместе с платежом.
+	Name() string
+	// Authorize блокирует сумму. Платёж может быть авторизован сразу или позже (через webhook).
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Payment, error)
+	// Capture списывает ранее заблокированную сумму.
+	Capture(ctx context.Context, paymentID string, amount int64) error
+	// Void снимает блокировку без списания.
+	Void(ctx context.Context, paymentID string) error
+	// Refund возвращает amount из списанной суммы.
+	Refund(ctx context.Context, paymentID string, amount int64) error
+	// ParseWebhook проверяет подпись и разбирает уведомление провайдера.
+	ParseWebhook(header http.Header, body []byte) (*Event, error)
+}
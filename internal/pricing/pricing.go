@@ -0,0 +1,209 @@
+// Package pricing рассчитывает стоимость брони по тарифам листинга.
+// Все суммы — целые числа в минимальных единицах валюты, чтобы не накапливать ошибки округления.
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"booking-service/internal/model"
+)
+
+// Единицы тарификации листинга.
+const (
+	UnitNight = "night" // посуточно: ночи между датами заезда и выезда
+	UnitHour  = "hour"  // почасово: неполный час считается целым
+)
+
+// ErrInvalidRules — тарифы листинга заданы некорректно (неизвестная единица, нет валюты, отрицательные суммы).
+var ErrInvalidRules = errors.New("invalid listing pricing")
+
+// Season — сезонный тариф на даты From..To включительно (YYYY-MM-DD) в часовом поясе листинга.
+type Season struct {
+	Name string
+	From string
+	To   string
+	Rate int64
+}
+
+// LongStayDiscount — скидка Percent процентов на проживание от MinUnits ночей (часов).
+type LongStayDiscount struct {
+	MinUnits int
+	Percent  float64
+}
+
+// Rules — тарифы листинга.
+type Rules struct {
+	Currency    string
+	Unit        string
+	BaseRate    int64
+	WeekendRate int64 // 0 — выходные по базовому тарифу
+	Seasons     []Season
+	CleaningFee int64
+	TaxRate     float64 // доля: 0.12 — 12%
+	LongStay    []LongStayDiscount
+}
+
+// Quote — расчёт стоимости брони.
+type Quote struct {
+	Currency string           `json:"currency"`
+	Unit     string           `json:"unit"`
+	Quantity int              `json:"quantity"` // ночей или часов
+	Items    model.PriceItems `json:"items"`
+	Total    int64            `json:"total_amount"`
+}
+
+// ToMinor переводит сумму из listing-service (в основных единицах, например 49.90) в минимальные единицы.
+func ToMinor(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// Calculate считает стоимость интервала [start, end) по правилам r. Даты, выходные и сезоны
+// определяются в часовом поясе листинга loc. Порядок: проживание → скидка за длительность →
+// уборка → налог на всё предыдущее.
+func Calculate(r Rules, start, end time.Time, loc *time.Location) (*Quote, error) {
+	if err := validate(r); err != nil {
+		return nil, err
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("%w: end must be after start", ErrInvalidRules)
+	}
+
+	units := chargeableUnits(r.Unit, start.In(loc), end.In(loc))
+	q := &Quote{Currency: r.Currency, Unit: r.Unit, Quantity: len(units)}
+
+	// Проживание: единицы с одинаковым тарифом собираются в одну строку
+	var lodging int64
+	index := map[string]int{}
+	for _, u := range units {
+		desc, rate := r.rateFor(u)
+		key := fmt.Sprintf("%s|%d", desc, rate)
+		if i, ok := index[key]; ok {
+			q.Items[i].Quantity++
+			q.Items[i].Amount += rate
+		} else {
+			index[key] = len(q.Items)
+			q.Items = append(q.Items, model.PriceItem{
+				Kind: model.PriceItemLodging, Description: desc, Quantity: 1, UnitAmount: rate, Amount: rate,
+			})
+		}
+		lodging += rate
+	}
+
+	total := lodging
+	if pct := r.longStayPercent(len(units)); pct > 0 {
+		amount := -int64(math.Round(float64(lodging) * pct / 100))
+		q.Items = append(q.Items, model.PriceItem{
+			Kind:        model.PriceItemDiscount,
+			Description: fmt.Sprintf("Long stay discount %g%%", pct),
+			Quantity:    1,
+			UnitAmount:  amount,
+			Amount:      amount,
+		})
+		total += amount
+	}
+	if r.CleaningFee > 0 {
+		q.Items = append(q.Items, model.PriceItem{
+			Kind: model.PriceItemCleaning, Description: "Cleaning fee", Quantity: 1, UnitAmount: r.CleaningFee, Amount: r.CleaningFee,
+		})
+		total += r.CleaningFee
+	}
+	if r.TaxRate > 0 {
+		tax := int64(math.Round(float64(total) * r.TaxRate))
+		q.Items = append(q.Items, model.PriceItem{
+			Kind:        model.PriceItemTax,
+			Description: fmt.Sprintf("Tax %g%%", r.TaxRate*100),
+			Quantity:    1,
+			UnitAmount:  tax,
+			Amount:      tax,
+		})
+		total += tax
+	}
+	q.Total = total
+	return q, nil
+}
+
+func validate(r Rules) error {
+	if r.Unit != UnitNight && r.Unit != UnitHour {
+		return fmt.Errorf("%w: unknown price unit %q", ErrInvalidRules, r.Unit)
+	}
+	if r.Currency == "" {
+		return fmt.Errorf("%w: currency is required", ErrInvalidRules)
+	}
+	if r.BaseRate < 0 || r.WeekendRate < 0 || r.CleaningFee < 0 || r.TaxRate < 0 {
+		return fmt.Errorf("%w: amounts must not be negative", ErrInvalidRules)
+	}
+	for _, s := range r.Seasons {
+		if s.Rate < 0 || s.From > s.To {
+			return fmt.Errorf("%w: invalid season %q", ErrInvalidRules, s.Name)
+		}
+	}
+	for _, d := range r.LongStay {
+		if d.Percent < 0 || d.Percent > 100 {
+			return fmt.Errorf("%w: discount percent must be between 0 and 100", ErrInvalidRules)
+		}
+	}
+	return nil
+}
+
+// unit — одна тарифицируемая ночь или час: локальная дата и признак выходного.
+type unit struct {
+	date    string // YYYY-MM-DD
+	weekend bool
+}
+
+// chargeableUnits раскладывает интервал на ночи или часы. Бронь короче суток при посуточном
+// тарифе стоит одну ночь. Выходные ночи — пятница и суббота, выходные часы — суббота и воскресенье.
+func chargeableUnits(unitKind string, start, end time.Time) []unit {
+	var units []unit
+	if unitKind == UnitHour {
+		for t := start; t.Before(end); t = t.Add(time.Hour) {
+			wd := t.Weekday()
+			units = append(units, unit{date: t.Format("2006-01-02"), weekend: wd == time.Saturday || wd == time.Sunday})
+		}
+		return units
+	}
+
+	y, m, d := start.Date()
+	ey, em, ed := end.Date()
+	last := time.Date(ey, em, ed, 0, 0, 0, 0, time.UTC)
+	for day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC); day.Before(last); day = day.AddDate(0, 0, 1) {
+		wd := day.Weekday()
+		units = append(units, unit{date: day.Format("2006-01-02"), weekend: wd == time.Friday || wd == time.Saturday})
+	}
+	if len(units) == 0 {
+		wd := start.Weekday()
+		units = append(units, unit{date: start.Format("2006-01-02"), weekend: wd == time.Friday || wd == time.Saturday})
+	}
+	return units
+}
+
+// rateFor возвращает тариф единицы: сезонный важнее выходного, выходной важнее базового.
+func (r Rules) rateFor(u unit) (string, int64) {
+	for _, s := range r.Seasons {
+		if s.From <= u.date && u.date <= s.To {
+			name := s.Name
+			if name == "" {
+				name = s.From + ".." + s.To
+			}
+			return "Seasonal rate: " + name, s.Rate
+		}
+	}
+	if u.weekend && r.WeekendRate > 0 {
+		return "Weekend rate", r.WeekendRate
+	}
+	return "Base rate", r.BaseRate
+}
+
+// longStayPercent возвращает наибольшую скидку, на которую тянет длительность units.
+func (r Rules) longStayPercent(units int) float64 {
+	var best float64
+	for _, d := range r.LongStay {
+		if units >= d.MinUnits && d.Percent > best {
+			best = d.Percent
+		}
+	}
+	return best
+}
@@ -0,0 +1,224 @@
+package pricing
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata" // тесты не должны зависеть от zoneinfo в системе
+
+	"booking-service/internal/model"
+)
+
+func lodging(desc string, qty int, rate int64) model.PriceItem {
+	return model.PriceItem{Kind: model.PriceItemLodging, Description: desc, Quantity: qty, UnitAmount: rate, Amount: int64(qty) * rate}
+}
+
+func single(kind, desc string, amount int64) model.PriceItem {
+	return model.PriceItem{Kind: kind, Description: desc, Quantity: 1, UnitAmount: amount, Amount: amount}
+}
+
+func TestCalculate(t *testing.T) {
+	almaty, err := time.LoadLocation("Asia/Almaty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Июнь 2025: 2-е — понедельник, 6-е — пятница, 7-е — суббота, 8-е — воскресенье
+	at := func(day, hour, minute int) time.Time { return time.Date(2025, 6, day, hour, minute, 0, 0, time.UTC) }
+	nightly := Rules{Currency: "USD", Unit: UnitNight, BaseRate: 10000, WeekendRate: 15000}
+	hourly := Rules{Currency: "USD", Unit: UnitHour, BaseRate: 2000, WeekendRate: 3000}
+
+	cases := []struct {
+		name       string
+		rules      Rules
+		start, end time.Time
+		loc        *time.Location
+		quantity   int
+		items      model.PriceItems
+		total      int64
+	}{
+		{
+			name: "weekday nights", rules: nightly, start: at(2, 15, 0), end: at(5, 11, 0),
+			quantity: 3, items: model.PriceItems{lodging("Base rate", 3, 10000)}, total: 30000,
+		},
+		{
+			name: "friday and saturday nights at weekend rate", rules: nightly, start: at(5, 15, 0), end: at(8, 11, 0),
+			quantity: 3,
+			items:    model.PriceItems{lodging("Base rate", 1, 10000), lodging("Weekend rate", 2, 15000)},
+			total:    40000,
+		},
+		{
+			name: "no weekend rate", rules: Rules{Currency: "USD", Unit: UnitNight, BaseRate: 10000}, start: at(6, 15, 0), end: at(8, 11, 0),
+			quantity: 2, items: model.PriceItems{lodging("Base rate", 2, 10000)}, total: 20000,
+		},
+		{
+			name: "same-day stay costs one night", rules: nightly, start: at(2, 10, 0), end: at(2, 18, 0),
+			quantity: 1, items: model.PriceItems{lodging("Base rate", 1, 10000)}, total: 10000,
+		},
+		{
+			name: "nights are counted in the listing time zone", rules: nightly, loc: almaty,
+			// 03:00 пятницы — 01:00 воскресенья по Алматы (UTC+5); в UTC это были бы ночи на четверг и пятницу
+			start: at(5, 22, 0), end: at(7, 20, 0),
+			quantity: 2, items: model.PriceItems{lodging("Weekend rate", 2, 15000)}, total: 30000,
+		},
+		{
+			name: "season overrides weekend rate",
+			rules: Rules{
+				Currency: "USD", Unit: UnitNight, BaseRate: 10000, WeekendRate: 15000,
+				Seasons: []Season{{Name: "Summer", From: "2025-06-06", To: "2025-06-07", Rate: 20000}},
+			},
+			start: at(5, 15, 0), end: at(8, 11, 0),
+			quantity: 3,
+			items:    model.PriceItems{lodging("Base rate", 1, 10000), lodging("Seasonal rate: Summer", 2, 20000)},
+			total:    50000,
+		},
+		{
+			name: "first matching season wins, unnamed season uses its dates",
+			rules: Rules{
+				Currency: "USD", Unit: UnitNight, BaseRate: 10000,
+				Seasons: []Season{
+					{From: "2025-06-03", To: "2025-06-03", Rate: 12000},
+					{Name: "Early June", From: "2025-06-01", To: "2025-06-10", Rate: 11000},
+				},
+			},
+			start: at(2, 15, 0), end: at(5, 11, 0),
+			quantity: 3,
+			items: model.PriceItems{
+				lodging("Seasonal rate: Early June", 2, 11000),
+				lodging("Seasonal rate: 2025-06-03..2025-06-03", 1, 12000),
+			},
+			total: 34000,
+		},
+		{
+			name: "largest applicable long-stay discount",
+			rules: Rules{
+				Currency: "USD", Unit: UnitNight, BaseRate: 10000,
+				LongStay: []LongStayDiscount{{MinUnits: 5, Percent: 5}, {MinUnits: 7, Percent: 10}, {MinUnits: 28, Percent: 20}},
+			},
+			start: at(2, 15, 0), end: at(9, 11, 0),
+			quantity: 7,
+			items:    model.PriceItems{lodging("Base rate", 7, 10000), single(model.PriceItemDiscount, "Long stay discount 10%", -7000)},
+			total:    63000,
+		},
+		{
+			name: "discount, cleaning and tax on top",
+			rules: Rules{
+				Currency: "USD", Unit: UnitNight, BaseRate: 10000, CleaningFee: 5000, TaxRate: 0.1,
+				LongStay: []LongStayDiscount{{MinUnits: 7, Percent: 10}},
+			},
+			start: at(2, 15, 0), end: at(9, 11, 0),
+			quantity: 7,
+			items: model.PriceItems{
+				lodging("Base rate", 7, 10000),
+				single(model.PriceItemDiscount, "Long stay discount 10%", -7000),
+				single(model.PriceItemCleaning, "Cleaning fee", 5000),
+				single(model.PriceItemTax, "Tax 10%", 6800),
+			},
+			total: 74800,
+		},
+		{
+			name:  "tax is rounded to the nearest minor unit",
+			rules: Rules{Currency: "USD", Unit: UnitNight, BaseRate: 9999, CleaningFee: 2500, TaxRate: 0.125},
+			start: at(2, 15, 0), end: at(3, 11, 0),
+			quantity: 1,
+			items: model.PriceItems{
+				lodging("Base rate", 1, 9999),
+				single(model.PriceItemCleaning, "Cleaning fee", 2500),
+				single(model.PriceItemTax, "Tax 12.5%", 1562), // 1562.375
+			},
+			total: 14061,
+		},
+		{
+			name:  "tax half rounds away from zero",
+			rules: Rules{Currency: "USD", Unit: UnitNight, BaseRate: 1004, TaxRate: 0.125},
+			start: at(2, 15, 0), end: at(3, 11, 0),
+			quantity: 1,
+			items:    model.PriceItems{lodging("Base rate", 1, 1004), single(model.PriceItemTax, "Tax 12.5%", 126)}, // 125.5
+			total:    1130,
+		},
+		{
+			name: "discount is rounded",
+			rules: Rules{
+				Currency: "USD", Unit: UnitNight, BaseRate: 3333,
+				LongStay: []LongStayDiscount{{MinUnits: 1, Percent: 15}},
+			},
+			start: at(2, 15, 0), end: at(3, 11, 0),
+			quantity: 1,
+			items:    model.PriceItems{lodging("Base rate", 1, 3333), single(model.PriceItemDiscount, "Long stay discount 15%", -500)}, // 499.95
+			total:    2833,
+		},
+		{
+			name: "hourly on a weekday", rules: hourly, start: at(2, 10, 0), end: at(2, 13, 0),
+			quantity: 3, items: model.PriceItems{lodging("Base rate", 3, 2000)}, total: 6000,
+		},
+		{
+			name: "partial hour counts as a whole hour", rules: hourly, start: at(2, 10, 0), end: at(2, 11, 30),
+			quantity: 2, items: model.PriceItems{lodging("Base rate", 2, 2000)}, total: 4000,
+		},
+		{
+			name: "hourly weekend is saturday and sunday", rules: hourly, start: at(6, 22, 0), end: at(7, 1, 0),
+			quantity: 3,
+			items:    model.PriceItems{lodging("Base rate", 2, 2000), lodging("Weekend rate", 1, 3000)},
+			total:    7000,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			loc := c.loc
+			if loc == nil {
+				loc = time.UTC
+			}
+			q, err := Calculate(c.rules, c.start, c.end, loc)
+			if err != nil {
+				t.Fatalf("Calculate: %v", err)
+			}
+			if q.Currency != c.rules.Currency || q.Unit != c.rules.Unit || q.Quantity != c.quantity {
+				t.Fatalf("quote = %s %s x%d, want %s %s x%d", q.Currency, q.Unit, q.Quantity, c.rules.Currency, c.rules.Unit, c.quantity)
+			}
+			if !reflect.DeepEqual(q.Items, c.items) {
+				t.Fatalf("items =\n%+v\nwant\n%+v", q.Items, c.items)
+			}
+			if q.Total != c.total {
+				t.Fatalf("total = %d, want %d", q.Total, c.total)
+			}
+		})
+	}
+}
+
+func TestCalculateRejectsInvalidRules(t *testing.T) {
+	start := time.Date(2025, 6, 2, 15, 0, 0, 0, time.UTC)
+	end := start.Add(48 * time.Hour)
+	valid := Rules{Currency: "USD", Unit: UnitNight, BaseRate: 10000}
+
+	cases := []struct {
+		name   string
+		modify func(r *Rules)
+		end    time.Time
+	}{
+		{"unknown unit", func(r *Rules) { r.Unit = "week" }, end},
+		{"no currency", func(r *Rules) { r.Currency = "" }, end},
+		{"negative base rate", func(r *Rules) { r.BaseRate = -1 }, end},
+		{"negative cleaning fee", func(r *Rules) { r.CleaningFee = -1 }, end},
+		{"negative tax", func(r *Rules) { r.TaxRate = -0.1 }, end},
+		{"season ends before it starts", func(r *Rules) { r.Seasons = []Season{{From: "2025-06-10", To: "2025-06-01", Rate: 1}} }, end},
+		{"discount above 100%", func(r *Rules) { r.LongStay = []LongStayDiscount{{MinUnits: 1, Percent: 120}} }, end},
+		{"empty interval", func(r *Rules) {}, start},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := valid
+			c.modify(&r)
+			if _, err := Calculate(r, start, c.end, time.UTC); !errors.Is(err, ErrInvalidRules) {
+				t.Fatalf("Calculate = %v, want %v", err, ErrInvalidRules)
+			}
+		})
+	}
+}
+
+func TestToMinor(t *testing.T) {
+	for amount, want := range map[float64]int64{49.90: 4990, 0.1 + 0.2: 30, 19.999: 2000, 0: 0, 1234.5: 123450} {
+		if got := ToMinor(amount); got != want {
+			t.Errorf("ToMinor(%v) = %d, want %d", amount, got, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Стабильные машиночитаемые коды ошибок API. Клиенты ориентируются на них, а не на текст.
+const (
+	CodeInvalidRequest        = "INVALID_REQUEST"
+	CodeRequestTooLarge       = "REQUEST_TOO_LARGE"
+	CodeInvalidTimeRange      = "INVALID_TIME_RANGE"
+	CodeStartInPast           = "START_IN_PAST"
+	CodeInsufficientNotice    = "INSUFFICIENT_NOTICE"
+	CodeBeyondHorizon         = "BEYOND_BOOKING_HORIZON"
+	CodeStayTooShort          = "STAY_TOO_SHORT"
+	CodeStayTooLong           = "STAY_TOO_LONG"
+	CodeCheckInDayNotAllowed  = "CHECK_IN_DAY_NOT_ALLOWED"
+	CodeCheckInTimeMismatch   = "CHECK_IN_TIME_MISMATCH"
+	CodeCheckOutTimeMismatch  = "CHECK_OUT_TIME_MISMATCH"
+	CodeInvalidCursor         = "INVALID_CURSOR"
+	CodeInvalidFilter         = "INVALID_FILTER"
+	CodeInvalidSchedule       = "INVALID_SCHEDULE"
+	CodeInvalidTimeZone       = "INVALID_TIME_ZONE"
+	CodeInvalidDateRange      = "INVALID_DATE_RANGE"
+	CodeInvalidPolicy         = "INVALID_CANCELLATION_POLICY"
+	CodeInvalidBookingRules   = "INVALID_BOOKING_RULES"
+	CodeInvalidCancellation   = "INVALID_CANCELLATION"
+	CodeInvalidModification   = "INVALID_MODIFICATION"
+	CodeUnauthorized          = "UNAUTHORIZED"
+	CodeForbidden             = "FORBIDDEN"
+	CodeBookingNotFound       = "BOOKING_NOT_FOUND"
+	CodeListingNotFound       = "LISTING_NOT_FOUND"
+	CodeUserNotFound          = "USER_NOT_FOUND"
+	CodeOwnerNotFound         = "OWNER_NOT_FOUND"
+	CodeOwnerMismatch         = "OWNER_MISMATCH"
+	CodePricingUnavailable    = "PRICING_UNAVAILABLE"
+	CodeInvalidGuests         = "INVALID_GUESTS"
+	CodeCapacityExceeded      = "CAPACITY_EXCEEDED"
+	CodePetsNotAllowed        = "PETS_NOT_ALLOWED"
+	CodePaymentDeclined       = "PAYMENT_DECLINED"
+	CodePaymentNotFound       = "PAYMENT_NOT_FOUND"
+	CodeInvalidPaymentWebhook = "INVALID_PAYMENT_WEBHOOK"
+	CodeSlotTaken             = "SLOT_TAKEN"
+	CodeInvalidTransition     = "INVALID_STATUS_TRANSITION"
+	CodeUpstreamFailure       = "UPSTREAM_UNAVAILABLE"
+	CodeInvalidWebhook        = "INVALID_WEBHOOK"
+	CodeWebhookNotFound       = "WEBHOOK_NOT_FOUND"
+	CodeDeliveryNotFound      = "DELIVERY_NOT_FOUND"
+	CodeIdempotencyKeyReused  = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyInProgress = "IDEMPOTENCY_IN_PROGRESS"
+	CodeInternal              = "INTERNAL_ERROR"
+)
+
+// ContentType — media type ответов с ошибками (RFC 7807).
+const ContentType = "application/problem+json"
+
+// Problem — тело ответа об ошибке в формате RFC 7807 с дополнительным полем code.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Code     string `json:"code"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// New собирает Problem: type строится из кода (SLOT_TAKEN → /problems/slot-taken),
+// title — стандартный текст HTTP-статуса.
+func New(status int, code, detail string) Problem {
+	return Problem{
+		Type:   "/problems/" + strings.ToLower(strings.ReplaceAll(code, "_", "-")),
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+// Write отправляет ответ application/problem+json. instance — путь запроса.
+func Write(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	p := New(status, code, detail)
+	p.Instance = r.URL.Path
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(p)
+}
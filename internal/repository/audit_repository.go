@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"booking-service/internal/audit"
+	"booking-service/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// insertAudit записывает изменение брони в журнал booking_audit в рамках транзакции tx.
+// Автор, ID запроса и IP берутся из audit.Info в ctx; before == nil — бронь только что создана.
+func insertAudit(ctx context.Context, tx *sqlx.Tx, action string, before, after *model.Booking) error {
+	var beforeJSON interface{} // nil → NULL
+	if before != nil {
+		raw, err := json.Marshal(before)
+		if err != nil {
+			return fmt.Errorf("insertAudit: %w", err)
+		}
+		beforeJSON = raw
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return fmt.Errorf("insertAudit: %w", err)
+	}
+
+	info := audit.FromContext(ctx)
+	query := `
+		INSERT INTO booking_audit (booking_id, action, actor_id, request_id, ip, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err = tx.ExecContext(ctx, query,
+		after.ID, action, nullString(info.ActorID), nullString(info.RequestID), nullString(info.IP), beforeJSON, afterJSON)
+	if err != nil {
+		return fmt.Errorf("insertAudit: %w", err)
+	}
+	return nil
+}
+
+// nullString превращает пустую строку в NULL.
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// ListHistory возвращает журнал изменений брони в порядке их выполнения.
+func (r *BookingRepository) ListHistory(ctx context.Context, bookingID string) ([]model.BookingAuditEntry, error) {
+	list := []model.BookingAuditEntry{}
+	query := "SELECT * FROM booking_audit WHERE booking_id = $1 ORDER BY id"
+	if err := r.db.SelectContext(ctx, &list, query, bookingID); err != nil {
+		return nil, fmt.Errorf("BookingRepository.ListHistory: %w", err)
+	}
+	return list, nil
+}
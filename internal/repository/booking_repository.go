@@ -2,13 +2,38 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"booking-service/internal/model"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// ErrStatusConflict возвращается UpdateStatus, если бронь не найдена в одном из ожидаемых статусов
+// (переход недопустим или статус успел поменяться параллельным запросом).
+var ErrStatusConflict = errors.New("booking status does not allow this transition")
+
+// ErrNotFound возвращается, если запрошенной брони нет (sql.ErrNoRows).
+var ErrNotFound = errors.New("booking not found")
+
+// ErrSlotTaken возвращается, если интервал брони пересекается с уже существующей активной бронью.
+var ErrSlotTaken = errors.New("listing is already booked for the given time range")
+
+// noOverlapConstraint — EXCLUDE-ограничение таблицы bookings (см. migrations/sql/0002_bookings_no_overlap.up.sql,
+// набор активных статусов обновлён в 0010_payments.up.sql, буферы броней учитываются с 0016_booking_buffers.up.sql).
+const noOverlapConstraint = "bookings_no_overlap"
+
+// isSlotTaken сообщает, что err — нарушение ограничения bookings_no_overlap.
+func isSlotTaken(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) &&
+		pqErr.Code == "23P01" && // exclusion_violation
+		pqErr.Constraint == noOverlapConstraint
+}
+
 type BookingRepository struct {
 	db *sqlx.DB
 }
@@ -18,46 +43,91 @@ func NewBookingRepository(db *sqlx.DB) *BookingRepository {
 }
 
 // Create вставляет новую запись в таблицу bookings и возвращает сгенерированный ID, created_at, updated_at.
+// Если интервал вместе с буфером брони пересекается с активной бронью того же листинга (и её буфером),
+// возвращает ErrSlotTaken —
+// это гарантирует сама БД, поэтому параллельные запросы не создадут двойную бронь.
+// В той же транзакции в outbox пишется событие BookingCreated, а в журнал booking_audit — запись о создании.
 func (r *BookingRepository) Create(ctx context.Context, b *model.Booking) error {
 	query := `
 		INSERT INTO bookings
-			(listing_id, user_id, owner_id, start_time, end_time, status)
+			(listing_id, user_id, owner_id, start_time, end_time, status, total_amount, currency, price_items,
+			 adults, children, pets, special_requests, metadata, buffer_before_minutes, buffer_after_minutes)
 		VALUES
-			($1, $2, $3, $4, $5, $6)
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		RETURNING id, created_at, updated_at
 	`
 
-	// Выполняем INSERT и читаем обратно поля ID/created_at/updated_at
-	err := r.db.QueryRowxContext(
-		ctx,
-		query,
-		b.ListingID,
-		b.UserID,
-		b.OwnerID,
-		b.StartTime,
-		b.EndTime,
-		b.Status, // Передаём статус в базу
-	).Scan(&b.ID, &b.CreatedAt, &b.UpdatedAt)
+	err := inTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		// Выполняем INSERT и читаем обратно поля ID/created_at/updated_at
+		err := tx.QueryRowxContext(
+			ctx,
+			query,
+			b.ListingID,
+			b.UserID,
+			b.OwnerID,
+			b.StartTime,
+			b.EndTime,
+			b.Status, // Передаём статус в базу
+			b.TotalAmount,
+			b.Currency,
+			b.PriceItems,
+			b.Adults,
+			b.Children,
+			b.Pets,
+			b.SpecialRequests,
+			b.Metadata,
+			b.BeforeMinutes,
+			b.AfterMinutes,
+		).Scan(&b.ID, &b.CreatedAt, &b.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		if err := insertAudit(ctx, tx, model.AuditCreated, nil, b); err != nil {
+			return err
+		}
+		return insertEvent(ctx, tx, model.EventBookingCreated, b)
+	})
 
+	if isSlotTaken(err) {
+		return fmt.Errorf("BookingRepository.Create: %w", ErrSlotTaken)
+	}
 	if err != nil {
 		return fmt.Errorf("BookingRepository.Create: %w", err)
 	}
 	return nil
 }
 
-// HasOverlap проверяет, существуют ли записи, пересекающиеся с [start, end) для данного listingID.
-func (r *BookingRepository) HasOverlap(ctx context.Context, listingID string, start, end time.Time) (bool, error) {
+// HasOverlap проверяет, существуют ли активные записи, пересекающиеся с [start, end) для данного listingID.
+// Как и в ограничении bookings_no_overlap, интервалы сравниваются вместе с буферами: буфером buf новой брони
+// и буферами, зафиксированными в существующих. Интервалы полуоткрытые: бронь, заканчивающаяся
+// (с учётом буферов) в start, не мешает.
+func (r *BookingRepository) HasOverlap(ctx context.Context, listingID string, start, end time.Time, buf model.Buffer) (bool, error) {
+	overlap, err := r.HasOverlapExcluding(ctx, listingID, start, end, buf, "")
+	if err != nil {
+		return false, fmt.Errorf("BookingRepository.HasOverlap: %w", err)
+	}
+	return overlap, nil
+}
+
+// HasOverlapExcluding — HasOverlap без учёта брони excludeID (пустая строка — не исключать ничего).
+// Нужна при изменении дат брони, которая иначе пересекалась бы сама с собой.
+func (r *BookingRepository) HasOverlapExcluding(ctx context.Context, listingID string, start, end time.Time, buf model.Buffer, excludeID string) (bool, error) {
 	var exists bool
 	query := `
 		SELECT EXISTS(
 			SELECT 1
 			FROM bookings
 			WHERE listing_id = $1
-			  AND tstzrange(start_time, end_time, '[]') && tstzrange($2, $3, '[]')
+			  AND status = ANY($4)
+			  AND booking_blocked_range(start_time, end_time, buffer_before_minutes, buffer_after_minutes)
+			      && booking_blocked_range($2, $3, $6, $7)
+			  AND ($5 = '' OR id::text <> $5)
 		)
 	`
-	if err := r.db.GetContext(ctx, &exists, query, listingID, start, end); err != nil {
-		return false, fmt.Errorf("BookingRepository.HasOverlap: %w", err)
+	err := r.db.GetContext(ctx, &exists, query,
+		listingID, start, end, pq.Array(model.ActiveStatuses), excludeID, buf.BeforeMinutes, buf.AfterMinutes)
+	if err != nil {
+		return false, fmt.Errorf("BookingRepository.HasOverlapExcluding: %w", err)
 	}
 	return exists, nil
 }
@@ -66,23 +136,254 @@ func (r *BookingRepository) HasOverlap(ctx context.Context, listingID string, st
 func (r *BookingRepository) GetByID(ctx context.Context, id string) (*model.Booking, error) {
 	var b model.Booking
 	query := "SELECT * FROM bookings WHERE id = $1"
-	if err := r.db.GetContext(ctx, &b, query, id); err != nil {
+	err := r.db.GetContext(ctx, &b, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("BookingRepository.GetByID: %w", ErrNotFound)
+	}
+	if err != nil {
 		return nil, fmt.Errorf("BookingRepository.GetByID: %w", err)
 	}
 	return &b, nil
 }
 
-// ListByUserID возвращает все брони, сделанные пользователем с userID.
-func (r *BookingRepository) ListByUserID(ctx context.Context, userID string) ([]model.Booking, error) {
-	var list []model.Booking
-	query := "SELECT * FROM bookings WHERE user_id = $1 ORDER BY start_time DESC"
-	if err := r.db.SelectContext(ctx, &list, query, userID); err != nil {
-		return nil, fmt.Errorf("BookingRepository.ListByUserID: %w", err)
+// lockBooking читает бронь id в транзакции tx и блокирует её строку до конца транзакции,
+// чтобы снимок «до» в журнале booking_audit совпадал с тем, что меняется.
+func lockBooking(ctx context.Context, tx *sqlx.Tx, id string) (*model.Booking, error) {
+	var b model.Booking
+	if err := tx.GetContext(ctx, &b, "SELECT * FROM bookings WHERE id = $1 FOR UPDATE", id); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// UpdateStatus атомарно переводит бронь в статус to, только если её текущий статус входит в from.
+// Если условие не выполнено, возвращает ErrStatusConflict. Событие о переходе (model.StatusEvents)
+// и запись в журнал booking_audit пишутся в той же транзакции.
+func (r *BookingRepository) UpdateStatus(ctx context.Context, id string, from []string, to string) (*model.Booking, error) {
+	query := `
+		UPDATE bookings
+		SET status = $1, updated_at = now()
+		WHERE id = $2
+		  AND status = ANY($3)
+		RETURNING *
+	`
+	var b model.Booking
+	err := inTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		before, err := lockBooking(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.GetContext(ctx, &b, query, to, id, pq.Array(from)); err != nil {
+			return err
+		}
+		if err := insertAudit(ctx, tx, model.AuditStatusChanged, before, &b); err != nil {
+			return err
+		}
+		if event, ok := model.StatusEvents[to]; ok {
+			return insertEvent(ctx, tx, event, &b)
+		}
+		return nil
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("BookingRepository.UpdateStatus: %w", ErrStatusConflict)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("BookingRepository.UpdateStatus: %w", err)
+	}
+	return &b, nil
+}
+
+// Cancel атомарно отменяет бронь, если её текущий статус входит в from, и записывает,
+// кто и почему её отменил и сколько вернуть гостю. Если условие не выполнено, возвращает ErrStatusConflict.
+// Событие BookingCancelled и запись в журнал booking_audit пишутся в той же транзакции.
+func (r *BookingRepository) Cancel(ctx context.Context, id string, from []string, c model.Cancellation) (*model.Booking, error) {
+	query := `
+		UPDATE bookings
+		SET status = $1,
+		    cancelled_by = $2,
+		    cancellation_reason = $3,
+		    refund_amount = $4,
+		    cancelled_at = now(),
+		    updated_at = now()
+		WHERE id = $5
+		  AND status = ANY($6)
+		RETURNING *
+	`
+	var b model.Booking
+	err := inTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		before, err := lockBooking(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		err = tx.GetContext(ctx, &b, query, model.StatusCancelled, c.CancelledBy, c.Reason, c.RefundAmount, id, pq.Array(from))
+		if err != nil {
+			return err
+		}
+		if err := insertAudit(ctx, tx, model.AuditCancelled, before, &b); err != nil {
+			return err
+		}
+		return insertEvent(ctx, tx, model.EventBookingCancelled, &b)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("BookingRepository.Cancel: %w", ErrStatusConflict)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("BookingRepository.Cancel: %w", err)
+	}
+	return &b, nil
+}
+
+// Reschedule атомарно меняет даты, буфер, цену и статус брони b.ID на значения из b, только если её текущий
+// статус всё ещё from, и записывает изменение m в booking_modifications. Если условие не выполнено,
+// возвращает ErrStatusConflict, если новый интервал занят — ErrSlotTaken. Событие BookingModified
+// и запись в журнал booking_audit пишутся в той же транзакции.
+func (r *BookingRepository) Reschedule(ctx context.Context, b *model.Booking, from string, m *model.BookingModification) (*model.Booking, error) {
+	query := `
+		UPDATE bookings
+		SET start_time = $1,
+		    end_time = $2,
+		    status = $3,
+		    total_amount = $4,
+		    currency = $5,
+		    price_items = $6,
+		    buffer_before_minutes = $7,
+		    buffer_after_minutes = $8,
+		    updated_at = now()
+		WHERE id = $9
+		  AND status = $10
+		RETURNING *
+	`
+	historyQuery := `
+		INSERT INTO booking_modifications
+			(booking_id, modified_by, old_start_time, old_end_time, new_start_time, new_end_time,
+			 old_total, new_total, old_status, new_status, reason)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at
+	`
+	var updated model.Booking
+	err := inTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		before, err := lockBooking(ctx, tx, b.ID)
+		if err != nil {
+			return err
+		}
+		err = tx.GetContext(ctx, &updated, query,
+			b.StartTime, b.EndTime, b.Status, b.TotalAmount, b.Currency, b.PriceItems,
+			b.BeforeMinutes, b.AfterMinutes, b.ID, from)
+		if err != nil {
+			return err
+		}
+		if err := insertAudit(ctx, tx, model.AuditModified, before, &updated); err != nil {
+			return err
+		}
+		err = tx.QueryRowxContext(ctx, historyQuery,
+			m.BookingID, m.ModifiedBy, m.OldStartTime, m.OldEndTime, m.NewStartTime, m.NewEndTime,
+			m.OldTotal, m.NewTotal, m.OldStatus, m.NewStatus, m.Reason,
+		).Scan(&m.ID, &m.CreatedAt)
+		if err != nil {
+			return err
+		}
+		return insertEvent(ctx, tx, model.EventBookingModified, &updated)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("BookingRepository.Reschedule: %w", ErrStatusConflict)
+	}
+	if isSlotTaken(err) {
+		return nil, fmt.Errorf("BookingRepository.Reschedule: %w", ErrSlotTaken)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("BookingRepository.Reschedule: %w", err)
+	}
+	return &updated, nil
+}
+
+// ListModifications возвращает историю изменений брони в порядке их выполнения.
+func (r *BookingRepository) ListModifications(ctx context.Context, bookingID string) ([]model.BookingModification, error) {
+	list := []model.BookingModification{}
+	query := "SELECT * FROM booking_modifications WHERE booking_id = $1 ORDER BY id"
+	if err := r.db.SelectContext(ctx, &list, query, bookingID); err != nil {
+		return nil, fmt.Errorf("BookingRepository.ListModifications: %w", err)
+	}
+	return list, nil
+}
+
+// ExpireUnpaid переводит в EXPIRED до limit броней, ожидающих оплаты с момента раньше olderThan
+// (с последнего перехода в PENDING_PAYMENT — при создании или изменении брони), и возвращает их.
+// Слот освобождается сразу; события BookingExpired и записи в журнал booking_audit пишутся в той же транзакции.
+func (r *BookingRepository) ExpireUnpaid(ctx context.Context, olderThan time.Time, limit int) ([]model.Booking, error) {
+	list := []model.Booking{}
+	selectQuery := `
+		SELECT * FROM bookings
+		WHERE status = $1
+		  AND updated_at < $2
+		ORDER BY updated_at
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`
+	updateQuery := `
+		UPDATE bookings
+		SET status = $1, updated_at = now()
+		WHERE id = $2
+		RETURNING *
+	`
+	err := inTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var unpaid []model.Booking
+		if err := tx.SelectContext(ctx, &unpaid, selectQuery, model.StatusPendingPayment, olderThan, limit); err != nil {
+			return err
+		}
+		for i := range unpaid {
+			var b model.Booking
+			if err := tx.GetContext(ctx, &b, updateQuery, model.StatusExpired, unpaid[i].ID); err != nil {
+				return err
+			}
+			if err := insertAudit(ctx, tx, model.AuditExpired, &unpaid[i], &b); err != nil {
+				return err
+			}
+			if err := insertEvent(ctx, tx, model.EventBookingExpired, &b); err != nil {
+				return err
+			}
+			list = append(list, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("BookingRepository.ExpireUnpaid: %w", err)
 	}
 	return list, nil
 }
 
-// IsAvailableAt проверяет, свободен ли listingID в момент timePoint.
+// ListByUserID возвращает страницу броней, сделанных пользователем с userID (по умолчанию — по start_time DESC).
+func (r *BookingRepository) ListByUserID(ctx context.Context, userID string, f ListFilter) (*Page, error) {
+	f.UserID = userID
+	page, err := r.list(ctx, withDefaults(f, "start_time"))
+	if err != nil {
+		return nil, fmt.Errorf("BookingRepository.ListByUserID: %w", err)
+	}
+	return page, nil
+}
+
+// ListByOwnerID возвращает страницу броней по листингам владельца ownerID (по умолчанию — по start_time DESC).
+func (r *BookingRepository) ListByOwnerID(ctx context.Context, ownerID string, f ListFilter) (*Page, error) {
+	f.OwnerID = ownerID
+	page, err := r.list(ctx, withDefaults(f, "start_time"))
+	if err != nil {
+		return nil, fmt.Errorf("BookingRepository.ListByOwnerID: %w", err)
+	}
+	return page, nil
+}
+
+// ListByListingID возвращает страницу броней листинга listingID (по умолчанию — по start_time DESC).
+func (r *BookingRepository) ListByListingID(ctx context.Context, listingID string, f ListFilter) (*Page, error) {
+	f.ListingID = listingID
+	page, err := r.list(ctx, withDefaults(f, "start_time"))
+	if err != nil {
+		return nil, fmt.Errorf("BookingRepository.ListByListingID: %w", err)
+	}
+	return page, nil
+}
+
+// IsAvailableAt проверяет, свободен ли listingID в момент timePoint: момент не попадает ни в одну
+// активную бронь, ни в её буфер.
 func (r *BookingRepository) IsAvailableAt(ctx context.Context, listingID string, timePoint time.Time) (bool, error) {
 	var overlap bool
 	query := `
@@ -90,41 +391,41 @@ func (r *BookingRepository) IsAvailableAt(ctx context.Context, listingID string,
 			SELECT 1 
 			FROM bookings 
 			WHERE listing_id = $1 
-			  AND $2 BETWEEN start_time AND end_time
+			  AND status = ANY($3)
+			  AND booking_blocked_range(start_time, end_time, buffer_before_minutes, buffer_after_minutes) @> $2::timestamptz
 		)
 	`
-	if err := r.db.GetContext(ctx, &overlap, query, listingID, timePoint); err != nil {
+	if err := r.db.GetContext(ctx, &overlap, query, listingID, timePoint, pq.Array(model.ActiveStatuses)); err != nil {
 		return false, fmt.Errorf("BookingRepository.IsAvailableAt: %w", err)
 	}
 	return !overlap, nil
 }
-func (r *BookingRepository) ListByListingAndDate(ctx context.Context, listingID string, date time.Time) ([]model.Booking, error) {
-	// «date» мы будем передавать так, что date = 2025-02-22 00:00:00 UTC.
-	// Тогда следующий день будет datePlus = 2025-02-23 00:00:00 UTC.
-	datePlus := date.Add(24 * time.Hour)
 
-	// Условие пересечения (start_time < datePlus) AND (end_time > date)
-	// Значит, часть брони лежит хоть одним часом на этом дне.
+// ListByListingInRange возвращает активные брони листинга, которые вместе со своими буферами пересекаются
+// с [from, to), по возрастанию start_time. Используется календарём доступности, чтобы не делать
+// отдельный запрос на каждый день.
+func (r *BookingRepository) ListByListingInRange(ctx context.Context, listingID string, from, to time.Time) ([]model.Booking, error) {
 	query := `
-		SELECT * 
+		SELECT *
 		FROM bookings
 		WHERE listing_id = $1
-		  AND start_time < $2
-		  AND end_time   > $3
+		  AND status = ANY($4)
+		  AND booking_blocked_range(start_time, end_time, buffer_before_minutes, buffer_after_minutes)
+		      && tstzrange($2, $3, '[)')
 		ORDER BY start_time
 	`
 	var list []model.Booking
-	if err := r.db.SelectContext(ctx, &list, query, listingID, datePlus, date); err != nil {
-		return nil, fmt.Errorf("BookingRepository.ListByListingAndDate: %w", err)
+	if err := r.db.SelectContext(ctx, &list, query, listingID, from, to, pq.Array(model.ActiveStatuses)); err != nil {
+		return nil, fmt.Errorf("BookingRepository.ListByListingInRange: %w", err)
 	}
 	return list, nil
 }
 
-func (r *BookingRepository) ListAllBookings(ctx context.Context) ([]model.Booking, error) {
-	var bookings []model.Booking
-	query := "SELECT * FROM bookings ORDER BY created_at DESC"
-	if err := r.db.SelectContext(ctx, &bookings, query); err != nil {
+// ListAllBookings возвращает страницу всех броней (по умолчанию — по created_at DESC).
+func (r *BookingRepository) ListAllBookings(ctx context.Context, f ListFilter) (*Page, error) {
+	page, err := r.list(ctx, withDefaults(f, "created_at"))
+	if err != nil {
 		return nil, fmt.Errorf("BookingRepository.ListAllBookings: %w", err)
 	}
-	return bookings, nil
+	return page, nil
 }
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"booking-service/internal/model"
+	"booking-service/internal/testdb"
+	"github.com/google/uuid"
+)
+
+// TestCreateConcurrentOverlap запускает параллельные Create пересекающихся броней одного листинга:
+// ровно одна должна пройти, остальные — получить ErrSlotTaken от ограничения bookings_no_overlap.
+func TestCreateConcurrentOverlap(t *testing.T) {
+	const n = 10
+	db := testdb.Open(t)
+	db.SetMaxOpenConns(n)
+	repo := NewBookingRepository(db)
+
+	listingID := "test-listing-" + uuid.NewString()
+	base := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+
+	var (
+		wg    sync.WaitGroup
+		start = make(chan struct{})
+		errs  = make([]error, n)
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Все интервалы длиной 2 часа сдвинуты на i минут и попарно пересекаются
+			b := &model.Booking{
+				ListingID: listingID,
+				UserID:    "test-user-" + uuid.NewString(),
+				OwnerID:   "test-owner",
+				StartTime: base.Add(time.Duration(i) * time.Minute),
+				EndTime:   base.Add(2*time.Hour + time.Duration(i)*time.Minute),
+				Status:    model.StatusPending,
+				Adults:    1,
+			}
+			<-start
+			errs[i] = repo.Create(context.Background(), b)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	var created, taken int
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case errors.Is(err, ErrSlotTaken):
+			taken++
+		default:
+			t.Errorf("create #%d: unexpected error: %v", i, err)
+		}
+	}
+	if created != 1 || taken != n-1 {
+		t.Fatalf("created %d, slot taken %d; want 1 and %d", created, taken, n-1)
+	}
+
+	var count int
+	query := "SELECT count(*) FROM bookings WHERE listing_id = $1"
+	if err := db.Get(&count, query, listingID); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("%d bookings stored, want 1", count)
+	}
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"booking-service/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type IdempotencyRepository struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyRepository(db *sqlx.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve пытается занять ключ (userID, key) на время ttl. Если ключ свободен или истёк,
+// создаёт «незавершённую» запись и возвращает её с created = true. Иначе возвращает
+// существующую запись с created = false — вызывающий решает, отдать ли сохранённый ответ.
+func (r *IdempotencyRepository) Reserve(
+	ctx context.Context,
+	userID, key, requestHash string,
+	ttl time.Duration,
+) (*model.IdempotencyRecord, bool, error) {
+	var rec model.IdempotencyRecord
+	query := `
+		INSERT INTO idempotency_keys (user_id, key, request_hash, expires_at)
+		VALUES ($1, $2, $3, now() + $4 * interval '1 second')
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET request_hash  = EXCLUDED.request_hash,
+		    status_code   = NULL,
+		    response_body = NULL,
+		    content_type  = NULL,
+		    created_at    = now(),
+		    expires_at    = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < now()
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, &rec, query, userID, key, requestHash, int64(ttl/time.Second))
+	if err == nil {
+		return &rec, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, fmt.Errorf("IdempotencyRepository.Reserve: %w", err)
+	}
+
+	// Ключ уже занят действующей записью — читаем её
+	query = "SELECT * FROM idempotency_keys WHERE user_id = $1 AND key = $2"
+	if err := r.db.GetContext(ctx, &rec, query, userID, key); err != nil {
+		return nil, false, fmt.Errorf("IdempotencyRepository.Reserve: %w", err)
+	}
+	return &rec, false, nil
+}
+
+// Complete сохраняет ответ на запрос, ранее зарезервированный через Reserve.
+func (r *IdempotencyRepository) Complete(ctx context.Context, userID, key string, status int, contentType string, body []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $3, content_type = $4, response_body = $5
+		WHERE user_id = $1 AND key = $2
+	`
+	if _, err := r.db.ExecContext(ctx, query, userID, key, status, contentType, body); err != nil {
+		return fmt.Errorf("IdempotencyRepository.Complete: %w", err)
+	}
+	return nil
+}
+
+// Release удаляет незавершённую запись, чтобы клиент мог повторить запрос с тем же ключом.
+func (r *IdempotencyRepository) Release(ctx context.Context, userID, key string) error {
+	query := "DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND status_code IS NULL"
+	if _, err := r.db.ExecContext(ctx, query, userID, key); err != nil {
+		return fmt.Errorf("IdempotencyRepository.Release: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired удаляет истёкшие ключи и возвращает их количество.
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at < now()")
+	if err != nil {
+		return 0, fmt.Errorf("IdempotencyRepository.DeleteExpired: %w", err)
+	}
+	return res.RowsAffected()
+}
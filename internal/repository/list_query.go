@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"booking-service/internal/model"
+	"github.com/lib/pq"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+var (
+	// ErrInvalidCursor — курсор не удалось разобрать или он выдан для другой сортировки.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidFilter — недопустимые параметры фильтрации или сортировки.
+	ErrInvalidFilter = errors.New("invalid list filter")
+)
+
+// sortColumns — поля, по которым разрешена сортировка (ключ — значение параметра sort).
+var sortColumns = map[string]string{
+	"start_time": "start_time",
+	"created_at": "created_at",
+}
+
+// ListFilter — общие параметры выборки списков броней: фильтры, сортировка и keyset-пагинация.
+// Пустые поля не участвуют в фильтрации.
+type ListFilter struct {
+	UserID    string
+	OwnerID   string
+	ListingID string
+	Statuses  []string
+	// From/To — окно дат: в выборку попадают брони, пересекающиеся с [From, To).
+	From *time.Time
+	To   *time.Time
+
+	SortBy string // "start_time" или "created_at"
+	Desc   bool
+	Limit  int
+	Cursor string
+}
+
+// Page — одна страница списка броней. NextCursor пуст, если страница последняя.
+type Page struct {
+	Items      []model.Booking `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// cursor — позиция последней записи страницы. Sort хранится, чтобы курсор
+// нельзя было применить к выборке с другой сортировкой.
+type cursor struct {
+	Sort  string    `json:"s"`
+	Desc  bool      `json:"d"`
+	Value time.Time `json:"v"`
+	ID    string    `json:"id"`
+}
+
+// withDefaults подставляет сортировку и лимит по умолчанию для конкретного списка.
+func withDefaults(f ListFilter, sortBy string) ListFilter {
+	if f.SortBy == "" {
+		f.SortBy = sortBy
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultPageLimit
+	}
+	return f
+}
+
+func encodeCursor(c cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// buildListQuery собирает SELECT по фильтру f. Запрашивается Limit+1 строк,
+// чтобы понять, есть ли следующая страница.
+func buildListQuery(f ListFilter) (string, []interface{}, error) {
+	column, ok := sortColumns[f.SortBy]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: unsupported sort %q", ErrInvalidFilter, f.SortBy)
+	}
+	if f.Limit < 1 || f.Limit > MaxPageLimit {
+		return "", nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidFilter, MaxPageLimit)
+	}
+	if f.From != nil && f.To != nil && !f.To.After(*f.From) {
+		return "", nil, fmt.Errorf("%w: 'to' must be after 'from'", ErrInvalidFilter)
+	}
+
+	var (
+		where []string
+		args  []interface{}
+	)
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.UserID != "" {
+		add("user_id = $%d", f.UserID)
+	}
+	if f.OwnerID != "" {
+		add("owner_id = $%d", f.OwnerID)
+	}
+	if f.ListingID != "" {
+		add("listing_id = $%d", f.ListingID)
+	}
+	if len(f.Statuses) > 0 {
+		add("status = ANY($%d)", pq.Array(f.Statuses))
+	}
+	if f.From != nil {
+		add("end_time > $%d", *f.From)
+	}
+	if f.To != nil {
+		add("start_time < $%d", *f.To)
+	}
+
+	op, dir := ">", "ASC"
+	if f.Desc {
+		op, dir = "<", "DESC"
+	}
+	if f.Cursor != "" {
+		c, err := decodeCursor(f.Cursor)
+		if err != nil {
+			return "", nil, err
+		}
+		if c.Sort != f.SortBy || c.Desc != f.Desc {
+			return "", nil, fmt.Errorf("%w: cursor was issued for a different sort order", ErrInvalidCursor)
+		}
+		args = append(args, c.Value, c.ID)
+		where = append(where, fmt.Sprintf("(%s, id) %s ($%d, $%d)", column, op, len(args)-1, len(args)))
+	}
+
+	query := "SELECT * FROM bookings"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, f.Limit+1)
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT $%d", column, dir, dir, len(args))
+	return query, args, nil
+}
+
+// list выполняет выборку по фильтру и формирует страницу с курсором на следующую.
+func (r *BookingRepository) list(ctx context.Context, f ListFilter) (*Page, error) {
+	query, args, err := buildListQuery(f)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []model.Booking{}
+	if err := r.db.SelectContext(ctx, &items, query, args...); err != nil {
+		return nil, err
+	}
+
+	page := &Page{Items: items}
+	if len(items) > f.Limit {
+		page.Items = items[:f.Limit]
+		last := page.Items[f.Limit-1]
+		value := last.StartTime
+		if f.SortBy == "created_at" {
+			value = last.CreatedAt
+		}
+		page.NextCursor = encodeCursor(cursor{Sort: f.SortBy, Desc: f.Desc, Value: value, ID: last.ID})
+	}
+	return page, nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := cursor{Sort: "start_time", Desc: true, Value: time.Date(2025, 3, 30, 10, 0, 0, 0, time.UTC), ID: "b-1"}
+	got, err := decodeCursor(encodeCursor(c))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Sort != c.Sort || got.Desc != c.Desc || !got.Value.Equal(c.Value) || got.ID != c.ID {
+		t.Fatalf("decoded %+v, want %+v", got, c)
+	}
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	valid := encodeCursor(cursor{Sort: "start_time", Value: time.Now(), ID: "b-1"})
+	cases := []struct {
+		name string
+		in   string
+	}{
+		{"not base64", "!!!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"s":"start_time","id":"b-1"}`))},
+		{"not json", base64.RawURLEncoding.EncodeToString([]byte("hello"))},
+		{"no id", base64.RawURLEncoding.EncodeToString([]byte(`{"s":"start_time","v":"2025-01-01T00:00:00Z"}`))},
+		{"bad time", base64.RawURLEncoding.EncodeToString([]byte(`{"s":"start_time","v":"yesterday","id":"b-1"}`))},
+		{"truncated", valid[:len(valid)/2]},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := decodeCursor(c.in); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("decodeCursor(%q) = %v, want %v", c.in, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestWithDefaults(t *testing.T) {
+	f := withDefaults(ListFilter{}, "created_at")
+	if f.SortBy != "created_at" || f.Limit != DefaultPageLimit {
+		t.Fatalf("defaults = sort %q limit %d, want created_at and %d", f.SortBy, f.Limit, DefaultPageLimit)
+	}
+	f = withDefaults(ListFilter{SortBy: "start_time", Limit: 10}, "created_at")
+	if f.SortBy != "start_time" || f.Limit != 10 {
+		t.Fatalf("explicit values overridden: sort %q limit %d", f.SortBy, f.Limit)
+	}
+}
+
+func TestBuildListQuery(t *testing.T) {
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(7 * 24 * time.Hour)
+	at := time.Date(2025, 6, 3, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name  string
+		f     ListFilter
+		query string
+		args  []interface{}
+	}{
+		{
+			name:  "no filters",
+			f:     ListFilter{SortBy: "start_time", Limit: 50},
+			query: "SELECT * FROM bookings ORDER BY start_time ASC, id ASC LIMIT $1",
+			args:  []interface{}{51},
+		},
+		{
+			name: "all filters descending",
+			f: ListFilter{
+				UserID: "u-1", OwnerID: "o-1", ListingID: "l-1",
+				Statuses: []string{"PENDING", "CONFIRMED"},
+				From:     &from, To: &to,
+				SortBy: "created_at", Desc: true, Limit: 10,
+			},
+			query: "SELECT * FROM bookings WHERE user_id = $1 AND owner_id = $2 AND listing_id = $3" +
+				" AND status = ANY($4) AND end_time > $5 AND start_time < $6" +
+				" ORDER BY created_at DESC, id DESC LIMIT $7",
+			args: []interface{}{"u-1", "o-1", "l-1", pq.Array([]string{"PENDING", "CONFIRMED"}), from, to, 11},
+		},
+		{
+			name: "cursor ascending",
+			f: ListFilter{
+				ListingID: "l-1", SortBy: "start_time", Limit: 1,
+				Cursor: encodeCursor(cursor{Sort: "start_time", Value: at, ID: "b-9"}),
+			},
+			query: "SELECT * FROM bookings WHERE listing_id = $1 AND (start_time, id) > ($2, $3)" +
+				" ORDER BY start_time ASC, id ASC LIMIT $4",
+			args: []interface{}{"l-1", at, "b-9", 2},
+		},
+		{
+			name: "cursor descending",
+			f: ListFilter{
+				SortBy: "created_at", Desc: true, Limit: 200,
+				Cursor: encodeCursor(cursor{Sort: "created_at", Desc: true, Value: at, ID: "b-9"}),
+			},
+			query: "SELECT * FROM bookings WHERE (created_at, id) < ($1, $2) ORDER BY created_at DESC, id DESC LIMIT $3",
+			args:  []interface{}{at, "b-9", 201},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			query, args, err := buildListQuery(c.f)
+			if err != nil {
+				t.Fatalf("buildListQuery: %v", err)
+			}
+			if query != c.query {
+				t.Fatalf("query =\n%s\nwant\n%s", query, c.query)
+			}
+			if !reflect.DeepEqual(args, c.args) {
+				t.Fatalf("args = %#v, want %#v", args, c.args)
+			}
+		})
+	}
+}
+
+func TestBuildListQueryRejects(t *testing.T) {
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	asc := encodeCursor(cursor{Sort: "start_time", Value: from, ID: "b-1"})
+
+	cases := []struct {
+		name string
+		f    ListFilter
+		want error
+	}{
+		{"unknown sort", ListFilter{SortBy: "price", Limit: 10}, ErrInvalidFilter},
+		{"zero limit", ListFilter{SortBy: "start_time", Limit: 0}, ErrInvalidFilter},
+		{"limit above max", ListFilter{SortBy: "start_time", Limit: MaxPageLimit + 1}, ErrInvalidFilter},
+		{"empty window", ListFilter{SortBy: "start_time", Limit: 10, From: &from, To: &from}, ErrInvalidFilter},
+		{"malformed cursor", ListFilter{SortBy: "start_time", Limit: 10, Cursor: "%%%"}, ErrInvalidCursor},
+		{"tampered cursor", ListFilter{SortBy: "start_time", Limit: 10, Cursor: asc[:len(asc)-3] + "AAA"}, ErrInvalidCursor},
+		{"cursor for other sort", ListFilter{SortBy: "created_at", Limit: 10, Cursor: asc}, ErrInvalidCursor},
+		{"cursor for other order", ListFilter{SortBy: "start_time", Desc: true, Limit: 10, Cursor: asc}, ErrInvalidCursor},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, _, err := buildListQuery(c.f); !errors.Is(err, c.want) {
+				t.Fatalf("buildListQuery = %v, want %v", err, c.want)
+			}
+		})
+	}
+}
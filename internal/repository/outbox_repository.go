@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"booking-service/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// insertEvent записывает доменное событие по брони b в outbox в рамках транзакции tx.
+func insertEvent(ctx context.Context, tx *sqlx.Tx, eventType string, b *model.Booking) error {
+	payload, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("insertEvent: %w", err)
+	}
+	query := "INSERT INTO outbox_events (aggregate_id, event_type, payload) VALUES ($1, $2, $3)"
+	if _, err := tx.ExecContext(ctx, query, b.ID, eventType, payload); err != nil {
+		return fmt.Errorf("insertEvent: %w", err)
+	}
+	return nil
+}
+
+type OutboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Публикация батча ограничена outboxPublishTimeout, а события закрепляются за relay на outboxLease —
+// заведомо дольше, чтобы другой экземпляр не подхватил батч, пока тот ещё публикуется.
+const (
+	outboxLease          = time.Minute
+	outboxPublishTimeout = 30 * time.Second
+)
+
+// ProcessPending берёт до limit неопубликованных событий (в порядке записи) и передаёт каждое в publish.
+// Успешно переданные помечаются published_at, для неудачных увеличивается attempts и запоминается ошибка.
+//
+// События одной брони публикуются строго по порядку: событие берётся, только когда все более ранние
+// события той же брони уже опубликованы, поэтому в батч попадает не больше одного события брони.
+// Событие, которое не удаётся опубликовать, задерживает следующие события своей брони до успешного повтора.
+//
+// События закрепляются за вызывающим короткой транзакцией (FOR UPDATE SKIP LOCKED + claimed_until),
+// а publish вызывается уже вне транзакции: медленный потребитель не держит ни транзакцию, ни блокировки
+// строк. Несколько экземпляров сервиса не публикуют одно событие одновременно; если экземпляр упал
+// посреди батча, события будут опубликованы повторно по истечении outboxLease.
+// Возвращает количество опубликованных событий.
+func (r *OutboxRepository) ProcessPending(
+	ctx context.Context,
+	limit int,
+	publish func(ctx context.Context, e model.OutboxEvent) error,
+) (int, error) {
+	events, err := r.claim(ctx, limit)
+	if err != nil {
+		return 0, fmt.Errorf("OutboxRepository.ProcessPending: %w", err)
+	}
+
+	pubCtx, cancel := context.WithTimeout(ctx, outboxPublishTimeout)
+	defer cancel()
+
+	published := 0
+	for _, e := range events {
+		if pubErr := publish(pubCtx, e); pubErr != nil {
+			query := "UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, claimed_until = NULL WHERE id = $1"
+			if _, err := r.db.ExecContext(ctx, query, e.ID, pubErr.Error()); err != nil {
+				return published, fmt.Errorf("OutboxRepository.ProcessPending: %w", err)
+			}
+			continue
+		}
+		query := `
+			UPDATE outbox_events
+			SET attempts = attempts + 1, last_error = NULL, published_at = now(), claimed_until = NULL
+			WHERE id = $1
+		`
+		if _, err := r.db.ExecContext(ctx, query, e.ID); err != nil {
+			return published, fmt.Errorf("OutboxRepository.ProcessPending: %w", err)
+		}
+		published++
+	}
+	return published, nil
+}
+
+// claim закрепляет за вызывающим до limit неопубликованных и никем не занятых событий на outboxLease,
+// пропуская события, перед которыми у той же брони есть неопубликованные.
+func (r *OutboxRepository) claim(ctx context.Context, limit int) ([]model.OutboxEvent, error) {
+	events := []model.OutboxEvent{}
+	query := `
+		UPDATE outbox_events
+		SET claimed_until = now() + $2 * interval '1 second'
+		WHERE id IN (
+			SELECT id FROM outbox_events
+			WHERE published_at IS NULL
+			  AND (claimed_until IS NULL OR claimed_until < now())
+			  AND NOT EXISTS (
+			      SELECT 1 FROM outbox_events earlier
+			      WHERE earlier.aggregate_id = outbox_events.aggregate_id
+			        AND earlier.published_at IS NULL
+			        AND earlier.id < outbox_events.id
+			  )
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`
+	if err := r.db.SelectContext(ctx, &events, query, limit, int64(outboxLease/time.Second)); err != nil {
+		return nil, err
+	}
+	// RETURNING не гарантирует порядок — публикуем в порядке записи
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
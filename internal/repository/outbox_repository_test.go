@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"booking-service/internal/model"
+	"booking-service/internal/testdb"
+	"github.com/google/uuid"
+)
+
+// TestProcessPendingKeepsAggregateOrder проверяет, что событие брони не публикуется,
+// пока не опубликовано предыдущее событие той же брони, даже если предыдущее не удалось отправить.
+func TestProcessPendingKeepsAggregateOrder(t *testing.T) {
+	db := testdb.Open(t)
+	bookings := NewBookingRepository(db)
+	outbox := NewOutboxRepository(db)
+	ctx := context.Background()
+
+	start := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	b := &model.Booking{
+		ListingID: "test-listing-" + uuid.NewString(),
+		UserID:    "test-user",
+		OwnerID:   "test-owner",
+		StartTime: start,
+		EndTime:   start.Add(2 * time.Hour),
+		Status:    model.StatusPending,
+		Adults:    1,
+	}
+	if err := bookings.Create(ctx, b); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := bookings.UpdateStatus(ctx, b.ID, []string{model.StatusPending}, model.StatusConfirmed); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+
+	// В базе могут быть события других тестов — записываем и роняем только события этой брони
+	var (
+		published []string
+		fail      = true
+	)
+	publish := func(_ context.Context, e model.OutboxEvent) error {
+		if e.AggregateID != b.ID {
+			return nil
+		}
+		if fail {
+			return errors.New("broker is down")
+		}
+		published = append(published, e.EventType)
+		return nil
+	}
+
+	// Первое событие не отправилось — второе не должно уйти раньше него
+	if _, err := outbox.ProcessPending(ctx, 1000, publish); err != nil {
+		t.Fatalf("first batch: %v", err)
+	}
+	var attempts []int
+	query := "SELECT attempts FROM outbox_events WHERE aggregate_id = $1 ORDER BY id"
+	if err := db.Select(&attempts, query, b.ID); err != nil {
+		t.Fatalf("attempts: %v", err)
+	}
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 0 {
+		t.Fatalf("attempts = %v, want [1 0]: the later event must wait for the failed one", attempts)
+	}
+
+	fail = false
+	for i := 0; i < 5 && len(published) < 2; i++ {
+		if _, err := outbox.ProcessPending(ctx, 1000, publish); err != nil {
+			t.Fatalf("batch %d: %v", i+2, err)
+		}
+	}
+	if len(published) != 2 || published[0] != model.EventBookingCreated || published[1] != model.EventBookingConfirmed {
+		t.Fatalf("published %v, want [%s %s]", published, model.EventBookingCreated, model.EventBookingConfirmed)
+	}
+}
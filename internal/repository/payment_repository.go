@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"booking-service/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrPaymentNotFound возвращается, если по брони (или ID провайдера) нет платёжного намерения.
+var ErrPaymentNotFound = errors.New("payment not found")
+
+type PaymentRepository struct {
+	db *sqlx.DB
+}
+
+func NewPaymentRepository(db *sqlx.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+// CreateIntent сохраняет платёжное намерение и заполняет ID, created_at, updated_at.
+func (r *PaymentRepository) CreateIntent(ctx context.Context, p *model.PaymentIntent) error {
+	query := `
+		INSERT INTO payment_intents
+			(booking_id, provider, provider_payment_id, amount, currency, status, client_secret)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+	err := r.db.QueryRowxContext(ctx, query,
+		p.BookingID, p.Provider, p.ProviderPaymentID, p.Amount, p.Currency, p.Status, p.ClientSecret,
+	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("PaymentRepository.CreateIntent: %w", err)
+	}
+	return nil
+}
+
+// GetByBookingID возвращает последнее платёжное намерение брони или ErrPaymentNotFound.
+func (r *PaymentRepository) GetByBookingID(ctx context.Context, bookingID string) (*model.PaymentIntent, error) {
+	var p model.PaymentIntent
+	query := "SELECT * FROM payment_intents WHERE booking_id = $1 ORDER BY created_at DESC LIMIT 1"
+	err := r.db.GetContext(ctx, &p, query, bookingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("PaymentRepository.GetByBookingID: %w", ErrPaymentNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("PaymentRepository.GetByBookingID: %w", err)
+	}
+	return &p, nil
+}
+
+// GetByProviderPaymentID возвращает платёжное намерение по ID платежа у провайдера или ErrPaymentNotFound.
+func (r *PaymentRepository) GetByProviderPaymentID(ctx context.Context, provider, providerPaymentID string) (*model.PaymentIntent, error) {
+	var p model.PaymentIntent
+	query := "SELECT * FROM payment_intents WHERE provider = $1 AND provider_payment_id = $2"
+	err := r.db.GetContext(ctx, &p, query, provider, providerPaymentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("PaymentRepository.GetByProviderPaymentID: %w", ErrPaymentNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("PaymentRepository.GetByProviderPaymentID: %w", err)
+	}
+	return &p, nil
+}
+
+// UpdateStatus меняет статус намерения и запоминает ошибку провайдера (nil — сбросить).
+func (r *PaymentRepository) UpdateStatus(ctx context.Context, id, status string, lastError *string) error {
+	query := "UPDATE payment_intents SET status = $2, last_error = $3, updated_at = now() WHERE id = $1"
+	if _, err := r.db.ExecContext(ctx, query, id, status, lastError); err != nil {
+		return fmt.Errorf("PaymentRepository.UpdateStatus: %w", err)
+	}
+	return nil
+}
+
+// AddRefund учитывает возврат amount; при полном возврате намерение получает статус REFUNDED.
+func (r *PaymentRepository) AddRefund(ctx context.Context, id string, amount int64) error {
+	query := `
+		UPDATE payment_intents
+		SET refunded_amount = refunded_amount + $2,
+		    status = CASE WHEN refunded_amount + $2 >= amount THEN 'REFUNDED' ELSE status END,
+		    updated_at = now()
+		WHERE id = $1
+	`
+	if _, err := r.db.ExecContext(ctx, query, id, amount); err != nil {
+		return fmt.Errorf("PaymentRepository.AddRefund: %w", err)
+	}
+	return nil
+}
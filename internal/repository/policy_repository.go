@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"booking-service/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type PolicyRepository struct {
+	db *sqlx.DB
+}
+
+func NewPolicyRepository(db *sqlx.DB) *PolicyRepository {
+	return &PolicyRepository{db: db}
+}
+
+// GetCancellationPolicy возвращает политику отмены листинга. Если политика не задана, ошибка оборачивает sql.ErrNoRows.
+func (r *PolicyRepository) GetCancellationPolicy(ctx context.Context, listingID string) (*model.CancellationPolicy, error) {
+	var p model.CancellationPolicy
+	query := "SELECT * FROM listing_cancellation_policies WHERE listing_id = $1"
+	if err := r.db.GetContext(ctx, &p, query, listingID); err != nil {
+		return nil, fmt.Errorf("PolicyRepository.GetCancellationPolicy: %w", err)
+	}
+	return &p, nil
+}
+
+// UpsertCancellationPolicy создаёт или полностью заменяет политику отмены листинга.
+func (r *PolicyRepository) UpsertCancellationPolicy(ctx context.Context, p *model.CancellationPolicy) error {
+	query := `
+		INSERT INTO listing_cancellation_policies (listing_id, kind, tiers)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (listing_id) DO UPDATE
+		SET kind       = EXCLUDED.kind,
+		    tiers      = EXCLUDED.tiers,
+		    updated_at = now()
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRowxContext(ctx, query, p.ListingID, p.Kind, p.Tiers).Scan(&p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("PolicyRepository.UpsertCancellationPolicy: %w", err)
+	}
+	return nil
+}
+
+// DeleteCancellationPolicy удаляет политику отмены листинга; после этого действует политика по умолчанию.
+func (r *PolicyRepository) DeleteCancellationPolicy(ctx context.Context, listingID string) error {
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM listing_cancellation_policies WHERE listing_id = $1", listingID); err != nil {
+		return fmt.Errorf("PolicyRepository.DeleteCancellationPolicy: %w", err)
+	}
+	return nil
+}
+
+// GetBookingRules возвращает правила бронирования листинга. Если правила не заданы, ошибка оборачивает sql.ErrNoRows.
+func (r *PolicyRepository) GetBookingRules(ctx context.Context, listingID string) (*model.BookingRules, error) {
+	var br model.BookingRules
+	query := "SELECT * FROM listing_booking_rules WHERE listing_id = $1"
+	if err := r.db.GetContext(ctx, &br, query, listingID); err != nil {
+		return nil, fmt.Errorf("PolicyRepository.GetBookingRules: %w", err)
+	}
+	return &br, nil
+}
+
+// UpsertBookingRules создаёт или полностью заменяет правила бронирования листинга.
+func (r *PolicyRepository) UpsertBookingRules(ctx context.Context, br *model.BookingRules) error {
+	query := `
+		INSERT INTO listing_booking_rules
+			(listing_id, min_duration_minutes, max_duration_minutes, min_notice_minutes, max_horizon_days,
+			 check_in_weekdays, check_in_time, check_out_time, buffer_before_minutes, buffer_after_minutes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (listing_id) DO UPDATE
+		SET min_duration_minutes = EXCLUDED.min_duration_minutes,
+		    max_duration_minutes = EXCLUDED.max_duration_minutes,
+		    min_notice_minutes   = EXCLUDED.min_notice_minutes,
+		    max_horizon_days     = EXCLUDED.max_horizon_days,
+		    check_in_weekdays    = EXCLUDED.check_in_weekdays,
+		    check_in_time        = EXCLUDED.check_in_time,
+		    check_out_time       = EXCLUDED.check_out_time,
+		    buffer_before_minutes = EXCLUDED.buffer_before_minutes,
+		    buffer_after_minutes  = EXCLUDED.buffer_after_minutes,
+		    updated_at           = now()
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRowxContext(ctx, query,
+		br.ListingID, br.MinDurationMinutes, br.MaxDurationMinutes, br.MinNoticeMinutes, br.MaxHorizonDays,
+		br.CheckInWeekdays, br.CheckInTime, br.CheckOutTime, br.BeforeMinutes, br.AfterMinutes,
+	).Scan(&br.CreatedAt, &br.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("PolicyRepository.UpsertBookingRules: %w", err)
+	}
+	return nil
+}
+
+// DeleteBookingRules удаляет правила бронирования листинга; после этого действуют правила по умолчанию.
+func (r *PolicyRepository) DeleteBookingRules(ctx context.Context, listingID string) error {
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM listing_booking_rules WHERE listing_id = $1", listingID); err != nil {
+		return fmt.Errorf("PolicyRepository.DeleteBookingRules: %w", err)
+	}
+	return nil
+}
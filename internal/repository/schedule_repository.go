@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"booking-service/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type ScheduleRepository struct {
+	db *sqlx.DB
+}
+
+func NewScheduleRepository(db *sqlx.DB) *ScheduleRepository {
+	return &ScheduleRepository{db: db}
+}
+
+// GetSchedule возвращает расписание листинга. Если расписание не задано, ошибка оборачивает sql.ErrNoRows.
+func (r *ScheduleRepository) GetSchedule(ctx context.Context, listingID string) (*model.ListingSchedule, error) {
+	var s model.ListingSchedule
+	query := "SELECT * FROM listing_schedules WHERE listing_id = $1"
+	if err := r.db.GetContext(ctx, &s, query, listingID); err != nil {
+		return nil, fmt.Errorf("ScheduleRepository.GetSchedule: %w", err)
+	}
+	return &s, nil
+}
+
+// UpsertSchedule создаёт или полностью заменяет расписание листинга.
+func (r *ScheduleRepository) UpsertSchedule(ctx context.Context, s *model.ListingSchedule) error {
+	query := `
+		INSERT INTO listing_schedules (listing_id, slot_minutes, weekly_hours, time_zone)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (listing_id) DO UPDATE
+		SET slot_minutes = EXCLUDED.slot_minutes,
+		    weekly_hours = EXCLUDED.weekly_hours,
+		    time_zone    = EXCLUDED.time_zone,
+		    updated_at   = now()
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRowxContext(ctx, query, s.ListingID, s.SlotMinutes, s.WeeklyHours, s.TimeZone).
+		Scan(&s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("ScheduleRepository.UpsertSchedule: %w", err)
+	}
+	return nil
+}
+
+// DeleteSchedule удаляет расписание листинга; после этого действует расписание по умолчанию.
+func (r *ScheduleRepository) DeleteSchedule(ctx context.Context, listingID string) error {
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM listing_schedules WHERE listing_id = $1", listingID); err != nil {
+		return fmt.Errorf("ScheduleRepository.DeleteSchedule: %w", err)
+	}
+	return nil
+}
+
+const closureColumns = "listing_id, date::text AS date, kind, reason, open_time, close_time, created_at"
+
+// ListClosures возвращает закрытые/праздничные дни листинга в диапазоне дат [from, to] (YYYY-MM-DD).
+func (r *ScheduleRepository) ListClosures(ctx context.Context, listingID, from, to string) ([]model.ListingClosure, error) {
+	list := []model.ListingClosure{}
+	query := "SELECT " + closureColumns + `
+		FROM listing_closures
+		WHERE listing_id = $1
+		  AND date BETWEEN $2::date AND $3::date
+		ORDER BY date
+	`
+	if err := r.db.SelectContext(ctx, &list, query, listingID, from, to); err != nil {
+		return nil, fmt.Errorf("ScheduleRepository.ListClosures: %w", err)
+	}
+	return list, nil
+}
+
+// UpsertClosure создаёт или заменяет закрытый/праздничный день листинга.
+func (r *ScheduleRepository) UpsertClosure(ctx context.Context, c *model.ListingClosure) error {
+	query := `
+		INSERT INTO listing_closures (listing_id, date, kind, reason, open_time, close_time)
+		VALUES ($1, $2::date, $3, $4, $5, $6)
+		ON CONFLICT (listing_id, date) DO UPDATE
+		SET kind       = EXCLUDED.kind,
+		    reason     = EXCLUDED.reason,
+		    open_time  = EXCLUDED.open_time,
+		    close_time = EXCLUDED.close_time
+		RETURNING created_at
+	`
+	err := r.db.QueryRowxContext(ctx, query, c.ListingID, c.Date, c.Kind, c.Reason, c.Open, c.Close).
+		Scan(&c.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ScheduleRepository.UpsertClosure: %w", err)
+	}
+	return nil
+}
+
+// DeleteClosure удаляет закрытый/праздничный день листинга.
+func (r *ScheduleRepository) DeleteClosure(ctx context.Context, listingID, date string) error {
+	query := "DELETE FROM listing_closures WHERE listing_id = $1 AND date = $2::date"
+	if _, err := r.db.ExecContext(ctx, query, listingID, date); err != nil {
+		return fmt.Errorf("ScheduleRepository.DeleteClosure: %w", err)
+	}
+	return nil
+}
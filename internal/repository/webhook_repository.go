@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"booking-service/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrSubscriptionNotFound возвращается, если подписки на вебхуки нет.
+var ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+
+// ErrDeliveryNotFound возвращается, если доставки вебхука нет.
+var ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+type WebhookRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// CreateSubscription сохраняет подписку и заполняет ID, created_at, updated_at.
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, s *model.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (owner_id, url, event_types, secret, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+	err := r.db.QueryRowxContext(ctx, query, s.OwnerID, s.URL, s.EventTypes, s.Secret, s.Active).
+		Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("WebhookRepository.CreateSubscription: %w", err)
+	}
+	return nil
+}
+
+// GetSubscription возвращает подписку по ID или ErrSubscriptionNotFound.
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id string) (*model.WebhookSubscription, error) {
+	var s model.WebhookSubscription
+	err := r.db.GetContext(ctx, &s, "SELECT * FROM webhook_subscriptions WHERE id = $1", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("WebhookRepository.GetSubscription: %w", ErrSubscriptionNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRepository.GetSubscription: %w", err)
+	}
+	return &s, nil
+}
+
+// ListSubscriptions возвращает подписки владельца ownerID, новые первыми.
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context, ownerID string) ([]model.WebhookSubscription, error) {
+	list := []model.WebhookSubscription{}
+	query := "SELECT * FROM webhook_subscriptions WHERE owner_id = $1 ORDER BY created_at DESC"
+	if err := r.db.SelectContext(ctx, &list, query, ownerID); err != nil {
+		return nil, fmt.Errorf("WebhookRepository.ListSubscriptions: %w", err)
+	}
+	return list, nil
+}
+
+// UpdateSubscription заменяет URL, типы событий, секрет и признак активности подписки.
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, s *model.WebhookSubscription) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $2, event_types = $3, secret = $4, active = $5, updated_at = now()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRowxContext(ctx, query, s.ID, s.URL, s.EventTypes, s.Secret, s.Active).
+		Scan(&s.CreatedAt, &s.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("WebhookRepository.UpdateSubscription: %w", ErrSubscriptionNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("WebhookRepository.UpdateSubscription: %w", err)
+	}
+	return nil
+}
+
+// DeleteSubscription удаляет подписку вместе с её доставками.
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("WebhookRepository.DeleteSubscription: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("WebhookRepository.DeleteSubscription: %w", ErrSubscriptionNotFound)
+	}
+	return nil
+}
+
+// EnqueueDeliveries ставит событие в очередь доставки всем активным подпискам владельца ownerID
+// на этот тип события. Повторный вызов для того же события ничего не добавляет.
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, ownerID string, eventID int64, eventType string, payload []byte) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		SELECT id, $2, $3, $4
+		FROM webhook_subscriptions
+		WHERE owner_id = $1
+		  AND active
+		  AND $3 = ANY(event_types)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`
+	res, err := r.db.ExecContext(ctx, query, ownerID, eventID, eventType, payload)
+	if err != nil {
+		return 0, fmt.Errorf("WebhookRepository.EnqueueDeliveries: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// ListDeliveries возвращает последние limit доставок подписки, новые первыми.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]model.WebhookDelivery, error) {
+	list := []model.WebhookDelivery{}
+	query := `
+		SELECT * FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	`
+	if err := r.db.SelectContext(ctx, &list, query, subscriptionID, limit); err != nil {
+		return nil, fmt.Errorf("WebhookRepository.ListDeliveries: %w", err)
+	}
+	return list, nil
+}
+
+// GetDelivery возвращает доставку вместе с журналом попыток или ErrDeliveryNotFound.
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id string) (*model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	err := r.db.GetContext(ctx, &d, "SELECT * FROM webhook_deliveries WHERE id = $1", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("WebhookRepository.GetDelivery: %w", ErrDeliveryNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRepository.GetDelivery: %w", err)
+	}
+
+	query := "SELECT * FROM webhook_delivery_attempts WHERE delivery_id = $1 ORDER BY id"
+	if err := r.db.SelectContext(ctx, &d.AttemptLog, query, id); err != nil {
+		return nil, fmt.Errorf("WebhookRepository.GetDelivery: %w", err)
+	}
+	return &d, nil
+}
+
+// ClaimDue забирает до limit доставок, которым пора уходить, и откладывает их следующую попытку
+// на lease — так параллельные воркеры не отправят одну доставку дважды, а доставка, воркер которой
+// упал, будет подхвачена снова после истечения lease.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]model.DueDelivery, error) {
+	list := []model.DueDelivery{}
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = now() + $2 * interval '1 second'
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id
+		  AND d.id IN (
+			SELECT d2.id
+			FROM webhook_deliveries d2
+			JOIN webhook_subscriptions s2 ON s2.id = d2.subscription_id AND s2.active
+			WHERE d2.status = 'PENDING'
+			  AND d2.next_attempt_at <= now()
+			ORDER BY d2.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d2 SKIP LOCKED
+		  )
+		RETURNING d.*, s.url, s.secret
+	`
+	if err := r.db.SelectContext(ctx, &list, query, limit, int64(lease/time.Second)); err != nil {
+		return nil, fmt.Errorf("WebhookRepository.ClaimDue: %w", err)
+	}
+	return list, nil
+}
+
+// RecordAttempt записывает попытку доставки и новое состояние доставки: status и время
+// следующей попытки (nil — попыток больше не будет).
+func (r *WebhookRepository) RecordAttempt(
+	ctx context.Context,
+	a *model.WebhookDeliveryAttempt,
+	status string,
+	nextAttemptAt *time.Time,
+) error {
+	err := inTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO webhook_delivery_attempts (delivery_id, status_code, error, duration_ms)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at
+		`
+		err := tx.QueryRowxContext(ctx, query, a.DeliveryID, a.StatusCode, a.Error, a.DurationMS).
+			Scan(&a.ID, &a.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		query = `
+			UPDATE webhook_deliveries
+			SET attempts = attempts + 1,
+			    status = $2,
+			    next_attempt_at = $3,
+			    last_status_code = $4,
+			    last_error = $5,
+			    updated_at = now()
+			WHERE id = $1
+		`
+		_, err = tx.ExecContext(ctx, query, a.DeliveryID, status, nextAttemptAt, a.StatusCode, a.Error)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("WebhookRepository.RecordAttempt: %w", err)
+	}
+	return nil
+}
+
+// Redeliver ставит доставку в очередь заново с обнулённым счётчиком попыток.
+func (r *WebhookRepository) Redeliver(ctx context.Context, id string) (*model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'PENDING', attempts = 0, next_attempt_at = now(), updated_at = now()
+		WHERE id = $1
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, &d, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("WebhookRepository.Redeliver: %w", ErrDeliveryNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRepository.Redeliver: %w", err)
+	}
+	return &d, nil
+}
@@ -0,0 +1,24 @@
+package service
+
+import "booking-service/internal/model"
+
+// Actor — пользователь, от имени которого выполняется операция (берётся из JWT).
+type Actor struct {
+	UserID string
+	Admin  bool
+}
+
+// IsOwnerOf сообщает, может ли actor действовать как владелец листинга брони.
+func (a Actor) IsOwnerOf(b *model.Booking) bool {
+	return a.Admin || b.OwnerID == a.UserID
+}
+
+// IsGuestOf сообщает, может ли actor действовать как гость брони.
+func (a Actor) IsGuestOf(b *model.Booking) bool {
+	return a.Admin || b.UserID == a.UserID
+}
+
+// CanView сообщает, может ли actor просматривать бронь.
+func (a Actor) CanView(b *model.Booking) bool {
+	return a.IsOwnerOf(b) || a.IsGuestOf(b)
+}
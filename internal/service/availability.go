@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"booking-service/internal/model"
+)
+
+// MaxAvailabilityDays — максимальная длина диапазона в AvailabilityRange.
+const MaxAvailabilityDays = 93
+
+// Interval — промежуток времени. *Local — в запрошенном часовом поясе (по умолчанию — поясе листинга).
+type Interval struct {
+	StartLocal time.Time `json:"start_local"`
+	EndLocal   time.Time `json:"end_local"`
+	StartUTC   time.Time `json:"start_utc"`
+	EndUTC     time.Time `json:"end_utc"`
+}
+
+func newInterval(start, end time.Time, displayLoc *time.Location) Interval {
+	return Interval{
+		StartLocal: start.In(displayLoc),
+		EndLocal:   end.In(displayLoc),
+		StartUTC:   start.UTC(),
+		EndUTC:     end.UTC(),
+	}
+}
+
+// Slot — один слот дня.
+type Slot struct {
+	Interval
+	Available bool `json:"available"`
+}
+
+// DayAvailability — доступность листинга на один календарный день в его часовом поясе.
+type DayAvailability struct {
+	Date          string          `json:"date"`
+	TimeZone      string          `json:"time_zone"` // пояс, в котором указаны *_local
+	Hours         map[string]bool `json:"hours"`     // начало слота "HH:MM" по времени листинга → свободен ли
+	Slots         []Slot          `json:"slots"`
+	FreeIntervals []Interval      `json:"free_intervals"` // свободные промежутки внутри часов работы
+}
+
+// RangeAvailability — доступность листинга по дням за диапазон [From, To].
+type RangeAvailability struct {
+	ListingID string            `json:"listing_id"`
+	From      string            `json:"from"`
+	To        string            `json:"to"`
+	TimeZone  string            `json:"time_zone"`
+	Days      []DayAvailability `json:"days"`
+}
+
+// AvailabilityRange считает доступность листинга на каждый день диапазона [fromStr, toStr]
+// (YYYY-MM-DD, включительно, по времени листинга). Все брони диапазона читаются одним запросом.
+func (s *BookingService) AvailabilityRange(ctx context.Context, listingID, fromStr, toStr, tz string) (*RangeAvailability, error) {
+	sch, loc, displayLoc, err := s.listingCalendar(ctx, listingID, tz)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", fromStr, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 'from' must be YYYY-MM-DD", ErrInvalidRange)
+	}
+	to, err := time.ParseInLocation("2006-01-02", toStr, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 'to' must be YYYY-MM-DD", ErrInvalidRange)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: 'to' must not be before 'from'", ErrInvalidRange)
+	}
+	if to.Sub(from) >= MaxAvailabilityDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: at most %d days per request", ErrInvalidRange, MaxAvailabilityDays)
+	}
+
+	closures, err := s.schedules.ListClosures(ctx, listingID, fromStr, toStr)
+	if err != nil {
+		return nil, fmt.Errorf("AvailabilityRange: %w", err)
+	}
+	closureByDate := make(map[string]*model.ListingClosure, len(closures))
+	for i := range closures {
+		closureByDate[closures[i].Date] = &closures[i]
+	}
+
+	rules, err := s.loadStayRules(ctx, listingID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("AvailabilityRange: %w", err)
+	}
+	now := time.Now()
+
+	y, m, d := to.Date()
+	end := time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	queryFrom, queryTo := rules.buffer.Around(from, end)
+	bookings, err := s.repo.ListByListingInRange(ctx, listingID, queryFrom, queryTo)
+	if err != nil {
+		return nil, fmt.Errorf("AvailabilityRange: %w", err)
+	}
+
+	res := &RangeAvailability{
+		ListingID: listingID,
+		From:      fromStr,
+		To:        toStr,
+		TimeZone:  displayLoc.String(),
+		Days:      []DayAvailability{},
+	}
+	for day := from; day.Before(end); {
+		dateStr := day.Format("2006-01-02")
+		res.Days = append(res.Days, buildDay(sch, closureByDate[dateStr], day, loc, displayLoc, bookings, rules, now))
+
+		y, m, d := day.Date()
+		day = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	}
+	return res, nil
+}
+
+// listingCalendar возвращает расписание листинга, его часовой пояс и пояс для отображения (tz или пояс листинга).
+func (s *BookingService) listingCalendar(ctx context.Context, listingID, tz string) (*model.ListingSchedule, *time.Location, *time.Location, error) {
+	sch, err := loadSchedule(ctx, s.schedules, listingID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("listing schedule: %w", err)
+	}
+	loc, err := loadLocation(sch.TimeZone)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("listing schedule: %w", err)
+	}
+	displayLoc := loc
+	if tz != "" {
+		if displayLoc, err = loadLocation(tz); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return sch, loc, displayLoc, nil
+}
+
+// buildDay считает слоты и свободные промежутки дня date (полночь в loc).
+// bookings могут включать брони за пределами дня — они просто ни с чем не пересекутся.
+// Брони занимают время вместе с буферами (см. busyIntervals).
+// Слот доступен, если он свободен и бронь с него можно начать по правилам листинга на момент now;
+// свободные промежутки обрезаются окном, в котором сейчас можно начать бронь.
+func buildDay(
+	sch *model.ListingSchedule,
+	closure *model.ListingClosure,
+	date time.Time,
+	loc, displayLoc *time.Location,
+	bookings []model.Booking,
+	rules *stayRules,
+	now time.Time,
+) DayAvailability {
+	day := DayAvailability{
+		Date:          date.Format("2006-01-02"),
+		TimeZone:      displayLoc.String(),
+		Hours:         map[string]bool{},
+		Slots:         []Slot{},
+		FreeIntervals: []Interval{},
+	}
+	busy := busyIntervals(bookings, rules.buffer)
+	for _, slot := range daySlots(sch, closure, date, loc) {
+		free := slotFree(slot, busy) && rules.bookable(slot.Start, now)
+		day.Hours[slot.Start.Format("15:04")] = free
+		day.Slots = append(day.Slots, Slot{Interval: newInterval(slot.Start, slot.End, displayLoc), Available: free})
+	}
+	window := rules.window(now)
+	for _, gap := range freeIntervals(dayOpenSlots(sch, closure, date, loc), busy) {
+		if gap.Start.Before(window.Start) {
+			gap.Start = window.Start
+		}
+		if !window.End.IsZero() && gap.End.After(window.End) {
+			gap.End = window.End
+		}
+		if gap.End.After(gap.Start) {
+			day.FreeIntervals = append(day.FreeIntervals, newInterval(gap.Start, gap.End, displayLoc))
+		}
+	}
+	return day
+}
+
+// dayOpenSlots возвращает рабочие интервалы дня целиком (без нарезки на слоты).
+func dayOpenSlots(sch *model.ListingSchedule, closure *model.ListingClosure, date time.Time, loc *time.Location) []timeSlot {
+	y, m, d := date.Date()
+	var res []timeSlot
+	for _, iv := range openIntervals(sch, closure, date) {
+		open := timeSlot{
+			Start: time.Date(y, m, d, 0, iv[0], 0, 0, loc),
+			End:   time.Date(y, m, d, 0, iv[1], 0, 0, loc),
+		}
+		if open.End.After(open.Start) {
+			res = append(res, open)
+		}
+	}
+	return res
+}
+
+// busyIntervals переводит брони в промежутки, пересекающиеся с которыми новая бронь с буфером buf
+// нарушила бы bookings_no_overlap: к интервалу брони с её собственными буферами добавляется уборка
+// новой брони перед ней (buf.AfterMinutes) и подготовка после неё (buf.BeforeMinutes).
+// Например, бронь до 11:00 с буфером после 2 часа занимает время до 13:00. Результат отсортирован по Start.
+func busyIntervals(bookings []model.Booking, buf model.Buffer) []timeSlot {
+	busy := make([]timeSlot, 0, len(bookings))
+	for _, b := range bookings {
+		start, end := b.Around(b.StartTime, b.EndTime)
+		busy = append(busy, timeSlot{
+			Start: start.Add(-time.Duration(buf.AfterMinutes) * time.Minute),
+			End:   end.Add(time.Duration(buf.BeforeMinutes) * time.Minute),
+		})
+	}
+	sort.Slice(busy, func(i, j int) bool { return busy[i].Start.Before(busy[j].Start) })
+	return busy
+}
+
+// freeIntervals вычитает занятые промежутки из рабочих интервалов и возвращает оставшиеся.
+// busy должны быть отсортированы по Start; с буферами они могут пересекаться друг с другом.
+func freeIntervals(open []timeSlot, busy []timeSlot) []timeSlot {
+	var gaps []timeSlot
+	for _, iv := range open {
+		cursor := iv.Start
+		for _, b := range busy {
+			if !b.End.After(cursor) || !b.Start.Before(iv.End) {
+				continue
+			}
+			if b.Start.After(cursor) {
+				gaps = append(gaps, timeSlot{Start: cursor, End: b.Start})
+			}
+			cursor = b.End
+		}
+		if cursor.Before(iv.End) {
+			gaps = append(gaps, timeSlot{Start: cursor, End: iv.End})
+		}
+	}
+	return gaps
+}
+
+// timeSlot — полуоткрытый интервал [Start, End).
+type timeSlot struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (s timeSlot) overlaps(start, end time.Time) bool {
+	return s.Start.Before(end) && start.Before(s.End)
+}
+
+// openIntervals возвращает рабочие интервалы дня date (в минутах от полуночи) с учётом
+// недельного расписания и закрытого/праздничного дня closure (может быть nil).
+func openIntervals(sch *model.ListingSchedule, closure *model.ListingClosure, date time.Time) [][2]int {
+	if closure != nil {
+		if closure.Kind == model.ClosureHoliday && closure.Open != nil && closure.Close != nil {
+			openMin, closeMin, err := parseInterval(*closure.Open, *closure.Close)
+			if err == nil {
+				return [][2]int{{openMin, closeMin}}
+			}
+		}
+		return nil
+	}
+
+	var intervals [][2]int
+	for _, h := range sch.WeeklyHours {
+		if h.Weekday != date.Weekday() {
+			continue
+		}
+		if openMin, closeMin, err := parseInterval(h.Open, h.Close); err == nil {
+			intervals = append(intervals, [2]int{openMin, closeMin})
+		}
+	}
+	return intervals
+}
+
+// daySlots нарезает рабочие интервалы дня date на слоты по sch.SlotMinutes в часовом поясе loc.
+// Неполный последний слот интервала отбрасывается. Границы слотов строятся через time.Date,
+// поэтому в день перехода на летнее время слоты из «пропавшего» часа схлопываются и пропускаются,
+// а в день перехода на зимнее повторившийся час достаётся слоту, который его пересекает.
+func daySlots(sch *model.ListingSchedule, closure *model.ListingClosure, date time.Time, loc *time.Location) []timeSlot {
+	y, m, d := date.Date()
+	step := sch.SlotMinutes
+	if step <= 0 {
+		step = defaultSlotMinutes
+	}
+
+	var slots []timeSlot
+	for _, iv := range openIntervals(sch, closure, date) {
+		for min := iv[0]; min+step <= iv[1]; min += step {
+			slot := timeSlot{
+				Start: time.Date(y, m, d, 0, min, 0, 0, loc),
+				End:   time.Date(y, m, d, 0, min+step, 0, 0, loc),
+			}
+			if !slot.End.After(slot.Start) {
+				continue
+			}
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+// slotFree сообщает, что слот не пересекается ни с одним из занятых промежутков.
+func slotFree(slot timeSlot, busy []timeSlot) bool {
+	for _, b := range busy {
+		if slot.overlaps(b.Start, b.End) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // тесты не должны зависеть от zoneinfo в системе
+
+	"booking-service/internal/model"
+)
+
+func mustLoc(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load %s: %v", name, err)
+	}
+	return loc
+}
+
+func strptr(s string) *string { return &s }
+
+// everyDay — расписание с одинаковыми часами на все дни недели.
+func everyDay(slotMinutes int, tz string, hours ...[2]string) *model.ListingSchedule {
+	sch := &model.ListingSchedule{SlotMinutes: slotMinutes, TimeZone: tz}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		for _, h := range hours {
+			sch.WeeklyHours = append(sch.WeeklyHours, model.OpeningHours{Weekday: d, Open: h[0], Close: h[1]})
+		}
+	}
+	return sch
+}
+
+// openRules — правила без ограничений: любой слот в будущем можно забронировать.
+func openRules(loc *time.Location) *stayRules {
+	return &stayRules{loc: loc, weekdays: map[time.Weekday]bool{}}
+}
+
+func starts(slots []timeSlot, loc *time.Location) []string {
+	res := make([]string, 0, len(slots))
+	for _, s := range slots {
+		res = append(res, s.Start.In(loc).Format("15:04"))
+	}
+	return res
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDaySlots(t *testing.T) {
+	berlin := mustLoc(t, "Europe/Berlin")
+	date := time.Date(2025, 6, 11, 0, 0, 0, 0, berlin) // среда
+
+	cases := []struct {
+		name    string
+		sch     *model.ListingSchedule
+		closure *model.ListingClosure
+		want    []string
+	}{
+		{"hourly", everyDay(60, "Europe/Berlin", [2]string{"09:00", "12:00"}), nil, []string{"09:00", "10:00", "11:00"}},
+		{"incomplete last slot is dropped", everyDay(60, "Europe/Berlin", [2]string{"09:00", "11:30"}), nil, []string{"09:00", "10:00"}},
+		{"default step", everyDay(0, "Europe/Berlin", [2]string{"09:00", "11:00"}), nil, []string{"09:00", "10:00"}},
+		{
+			"lunch break", everyDay(30, "Europe/Berlin", [2]string{"09:00", "10:00"}, [2]string{"13:00", "14:00"}), nil,
+			[]string{"09:00", "09:30", "13:00", "13:30"},
+		},
+		{
+			"other weekday", &model.ListingSchedule{SlotMinutes: 60, WeeklyHours: model.WeeklyHours{
+				{Weekday: time.Monday, Open: "09:00", Close: "12:00"},
+			}}, nil, []string{},
+		},
+		{
+			"blackout", everyDay(60, "Europe/Berlin", [2]string{"09:00", "12:00"}),
+			&model.ListingClosure{Kind: model.ClosureBlackout}, []string{},
+		},
+		{
+			"holiday without hours", everyDay(60, "Europe/Berlin", [2]string{"09:00", "12:00"}),
+			&model.ListingClosure{Kind: model.ClosureHoliday}, []string{},
+		},
+		{
+			"holiday with special hours", everyDay(60, "Europe/Berlin", [2]string{"09:00", "12:00"}),
+			&model.ListingClosure{Kind: model.ClosureHoliday, Open: strptr("14:00"), Close: strptr("16:00")},
+			[]string{"14:00", "15:00"},
+		},
+		{"until midnight", everyDay(120, "Europe/Berlin", [2]string{"20:00", "24:00"}), nil, []string{"20:00", "22:00"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := starts(daySlots(c.sch, c.closure, date, berlin), berlin)
+			if !equalStrings(got, c.want) {
+				t.Fatalf("slots = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+// TestDaySlotsDST проверяет дни перехода на летнее (23 часа) и зимнее (25 часов) время:
+// слоты идут без разрывов и наложений и покрывают сутки целиком.
+func TestDaySlotsDST(t *testing.T) {
+	berlin := mustLoc(t, "Europe/Berlin")
+	sch := everyDay(60, "Europe/Berlin", [2]string{"00:00", "24:00"})
+
+	cases := []struct {
+		name   string
+		date   time.Time
+		slots  int
+		length time.Duration
+		absent string // начало слота, которого в этот день нет
+	}{
+		{"spring forward", time.Date(2025, 3, 30, 0, 0, 0, 0, berlin), 23, 23 * time.Hour, "02:00"},
+		{"fall back", time.Date(2025, 10, 26, 0, 0, 0, 0, berlin), 24, 25 * time.Hour, ""},
+		{"regular day", time.Date(2025, 10, 27, 0, 0, 0, 0, berlin), 24, 24 * time.Hour, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			slots := daySlots(sch, nil, c.date, berlin)
+			if len(slots) != c.slots {
+				t.Fatalf("%d slots, want %d: %v", len(slots), c.slots, starts(slots, berlin))
+			}
+			if !slots[0].Start.Equal(c.date) {
+				t.Fatalf("first slot starts at %s, want %s", slots[0].Start, c.date)
+			}
+			for i := 1; i < len(slots); i++ {
+				if !slots[i].Start.Equal(slots[i-1].End) {
+					t.Fatalf("slot %d starts at %s, previous ends at %s", i, slots[i].Start, slots[i-1].End)
+				}
+			}
+			if got := slots[len(slots)-1].End.Sub(slots[0].Start); got != c.length {
+				t.Fatalf("slots cover %s, want %s", got, c.length)
+			}
+			for _, s := range starts(slots, berlin) {
+				if c.absent != "" && s == c.absent {
+					t.Fatalf("slot %s exists on %s", s, c.date.Format("2006-01-02"))
+				}
+			}
+		})
+	}
+}
+
+func TestBusyIntervals(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2025, 6, 11, h, 0, 0, 0, time.UTC) }
+	bookings := []model.Booking{
+		{StartTime: at(14), EndTime: at(16), Buffer: model.Buffer{BeforeMinutes: 30, AfterMinutes: 60}},
+		{StartTime: at(9), EndTime: at(11)},
+	}
+
+	busy := busyIntervals(bookings, model.Buffer{BeforeMinutes: 15, AfterMinutes: 120})
+	want := []timeSlot{
+		// Без собственных буферов: уборка новой брони (2 ч) перед ней и подготовка (15 мин) после
+		{Start: at(7), End: at(11).Add(15 * time.Minute)},
+		// Свои буферы (30 мин до, 1 ч после) плюс буферы новой брони
+		{Start: at(11).Add(30 * time.Minute), End: at(17).Add(15 * time.Minute)},
+	}
+	if len(busy) != len(want) {
+		t.Fatalf("busy = %v, want %v", busy, want)
+	}
+	for i := range want {
+		if !busy[i].Start.Equal(want[i].Start) || !busy[i].End.Equal(want[i].End) {
+			t.Fatalf("busy[%d] = %v, want %v", i, busy[i], want[i])
+		}
+	}
+}
+
+func TestFreeIntervals(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2025, 6, 11, h, m, 0, 0, time.UTC) }
+	iv := func(h1, m1, h2, m2 int) timeSlot { return timeSlot{Start: at(h1, m1), End: at(h2, m2)} }
+
+	cases := []struct {
+		name string
+		open []timeSlot
+		busy []timeSlot
+		want []timeSlot
+	}{
+		{"nothing booked", []timeSlot{iv(9, 0, 18, 0)}, nil, []timeSlot{iv(9, 0, 18, 0)}},
+		{"booking inside", []timeSlot{iv(9, 0, 18, 0)}, []timeSlot{iv(12, 0, 13, 0)}, []timeSlot{iv(9, 0, 12, 0), iv(13, 0, 18, 0)}},
+		{
+			"overlapping busy intervals", []timeSlot{iv(9, 0, 18, 0)},
+			[]timeSlot{iv(10, 0, 12, 0), iv(11, 0, 11, 30), iv(11, 30, 14, 0)},
+			[]timeSlot{iv(9, 0, 10, 0), iv(14, 0, 18, 0)},
+		},
+		{"adjacent busy intervals", []timeSlot{iv(9, 0, 18, 0)}, []timeSlot{iv(10, 0, 11, 0), iv(11, 0, 12, 0)}, []timeSlot{iv(9, 0, 10, 0), iv(12, 0, 18, 0)}},
+		{"busy across opening", []timeSlot{iv(9, 0, 18, 0)}, []timeSlot{iv(7, 0, 10, 0)}, []timeSlot{iv(10, 0, 18, 0)}},
+		{"busy across closing", []timeSlot{iv(9, 0, 18, 0)}, []timeSlot{iv(17, 0, 20, 0)}, []timeSlot{iv(9, 0, 17, 0)}},
+		{"busy touching edges", []timeSlot{iv(9, 0, 18, 0)}, []timeSlot{iv(7, 0, 9, 0), iv(18, 0, 19, 0)}, []timeSlot{iv(9, 0, 18, 0)}},
+		{"whole day busy", []timeSlot{iv(9, 0, 18, 0)}, []timeSlot{iv(8, 0, 19, 0)}, nil},
+		{
+			"busy across lunch break", []timeSlot{iv(9, 0, 12, 0), iv(13, 0, 18, 0)},
+			[]timeSlot{iv(11, 0, 14, 0)},
+			[]timeSlot{iv(9, 0, 11, 0), iv(14, 0, 18, 0)},
+		},
+		{"closed day", nil, []timeSlot{iv(11, 0, 14, 0)}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := freeIntervals(c.open, c.busy)
+			if len(got) != len(c.want) {
+				t.Fatalf("free = %v, want %v", got, c.want)
+			}
+			for i := range got {
+				if !got[i].Start.Equal(c.want[i].Start) || !got[i].End.Equal(c.want[i].End) {
+					t.Fatalf("free = %v, want %v", got, c.want)
+				}
+			}
+		})
+	}
+}
+
+// TestBuildDayBuffersAcrossMidnight проверяет, что буферы броней соседних дней занимают
+// слоты и свободное время на границах дня.
+func TestBuildDayBuffersAcrossMidnight(t *testing.T) {
+	almaty := mustLoc(t, "Asia/Almaty")
+	date := time.Date(2025, 6, 11, 0, 0, 0, 0, almaty)
+	sch := everyDay(60, "Asia/Almaty", [2]string{"00:00", "24:00"})
+	rules := openRules(almaty)
+	rules.buffer = model.Buffer{BeforeMinutes: 60}
+	now := date.Add(-48 * time.Hour)
+
+	bookings := []model.Booking{
+		// Вчерашняя бронь до 23:00 с уборкой 3 часа и подготовкой новой брони 1 час: занято до 03:00
+		{StartTime: date.Add(-5 * time.Hour), EndTime: date.Add(-time.Hour), Buffer: model.Buffer{AfterMinutes: 180}},
+		// Завтрашняя бронь с 00:30 с подготовкой 1 час: новая бронь должна закончиться до 23:30
+		{StartTime: date.Add(24*time.Hour + 30*time.Minute), EndTime: date.Add(26 * time.Hour), Buffer: model.Buffer{BeforeMinutes: 60}},
+	}
+
+	day := buildDay(sch, nil, date, almaty, almaty, bookings, rules, now)
+	if len(day.Slots) != 24 {
+		t.Fatalf("%d slots, want 24", len(day.Slots))
+	}
+	for hour, want := range map[string]bool{"00:00": false, "02:00": false, "03:00": true, "22:00": true, "23:00": false} {
+		if got := day.Hours[hour]; got != want {
+			t.Errorf("slot %s available = %v, want %v", hour, got, want)
+		}
+	}
+
+	if len(day.FreeIntervals) != 1 {
+		t.Fatalf("free intervals = %+v, want one", day.FreeIntervals)
+	}
+	free := day.FreeIntervals[0]
+	if !free.StartUTC.Equal(date.Add(3*time.Hour)) || !free.EndUTC.Equal(date.Add(23*time.Hour+30*time.Minute)) {
+		t.Fatalf("free interval = %s..%s, want 03:00..23:30", free.StartLocal.Format("15:04"), free.EndLocal.Format("15:04"))
+	}
+}
+
+func TestBuildDayNoticeAndDisplayZone(t *testing.T) {
+	berlin := mustLoc(t, "Europe/Berlin")
+	date := time.Date(2025, 10, 26, 0, 0, 0, 0, berlin) // 25 часов
+	sch := everyDay(60, "Europe/Berlin", [2]string{"00:00", "24:00"})
+	rules := openRules(berlin)
+	rules.minNotice = 2 * time.Hour
+	now := time.Date(2025, 10, 26, 10, 30, 0, 0, berlin)
+
+	day := buildDay(sch, nil, date, berlin, time.UTC, nil, rules, now)
+	if day.Date != "2025-10-26" || day.TimeZone != "UTC" {
+		t.Fatalf("day %s in %s, want 2025-10-26 in UTC", day.Date, day.TimeZone)
+	}
+	if len(day.Hours) != 24 {
+		t.Fatalf("%d hours, want 24", len(day.Hours))
+	}
+	for hour, want := range map[string]bool{"12:00": false, "13:00": true, "23:00": true} {
+		if got := day.Hours[hour]; got != want {
+			t.Errorf("slot %s available = %v, want %v", hour, got, want)
+		}
+	}
+	if len(day.FreeIntervals) != 1 {
+		t.Fatalf("free intervals = %+v, want one", day.FreeIntervals)
+	}
+	free := day.FreeIntervals[0]
+	if !free.StartUTC.Equal(now.Add(rules.minNotice)) || free.StartLocal.Location() != time.UTC {
+		t.Fatalf("free interval starts at %s, want %s in UTC", free.StartLocal, now.Add(rules.minNotice).UTC())
+	}
+}
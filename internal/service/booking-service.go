@@ -4,10 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
-	"net/http"
 	"time"
 
+	"booking-service/internal/clients"
 	"booking-service/internal/model"
 	"booking-service/internal/repository"
 )
@@ -19,87 +18,198 @@ type CreateBookingRequest struct {
 	StartTime  time.Time `json:"start_time"`
 	EndTime    time.Time `json:"end_time"`
 	AuthHeader string    // Bearer <token>
+
+	// Состав гостей (Adults по умолчанию 1), пожелания и метаданные клиента
+	Adults          int            `json:"adults"`
+	Children        int            `json:"children"`
+	Pets            int            `json:"pets"`
+	SpecialRequests string         `json:"special_requests"`
+	Metadata        model.Metadata `json:"metadata"`
 }
 type BookingService struct {
-	repo              *repository.BookingRepository
-	userServiceURL    string
-	listingServiceURL string
-	httpClient        *http.Client
+	repo      *repository.BookingRepository
+	schedules *repository.ScheduleRepository
+	policies  *repository.PolicyRepository
+	users     clients.UserClient
+	listings  clients.ListingClient
+	payments  *PaymentService
 }
 
 func NewBookingService(
 	repo *repository.BookingRepository,
-	userSvcURL, listingSvcURL string,
+	schedules *repository.ScheduleRepository,
+	policies *repository.PolicyRepository,
+	users clients.UserClient,
+	listings clients.ListingClient,
+	payments *PaymentService,
 ) *BookingService {
 	return &BookingService{
-		repo:              repo,
-		userServiceURL:    userSvcURL,
-		listingServiceURL: listingSvcURL,
-		httpClient:        &http.Client{Timeout: 5 * time.Second},
+		repo:      repo,
+		schedules: schedules,
+		policies:  policies,
+		users:     users,
+		listings:  listings,
+		payments:  payments,
 	}
 }
 
 func (s *BookingService) CreateBooking(ctx context.Context, req *CreateBookingRequest) (*model.Booking, error) {
-	// 1) Проверяем, что end_time > start_time
+	// 1) Проверяем, что end_time > start_time, и данные о гостях
 	if !req.EndTime.After(req.StartTime) {
-		return nil, errors.New("end_time must be after start_time")
+		return nil, ErrInvalidTimeRange
+	}
+	if err := normalizeGuests(req); err != nil {
+		return nil, err
 	}
 
-	// 2) Проверка через User Service (убедиться, что userID и ownerID существуют)
-	if err := s.checkUserExists(req.UserID, req.AuthHeader); err != nil {
-		return nil, fmt.Errorf("user validation failed: %w", err)
+	// 2) Листинг из Listing Service: он же — источник владельца брони.
+	//    owner_id в запросе необязателен, но если передан, должен совпадать с владельцем листинга.
+	listing, err := fetchListing(ctx, s.listings, req.ListingID, req.AuthHeader)
+	if err != nil {
+		return nil, fmt.Errorf("listing validation failed: %w", err)
 	}
-	if err := s.checkUserExists(req.OwnerID, req.AuthHeader); err != nil {
-		return nil, fmt.Errorf("owner validation failed: %w", err)
+	if req.OwnerID != "" && req.OwnerID != listing.OwnerID {
+		return nil, ErrOwnerMismatch
+	}
+	//    Гости должны поместиться в листинг, питомцы — быть разрешены
+	if err := checkGuestPolicy(listing, req.Adults, req.Children, req.Pets); err != nil {
+		return nil, err
+	}
+	//    Даты должны укладываться в правила бронирования листинга
+	rules, err := s.loadStayRules(ctx, req.ListingID, listing)
+	if err != nil {
+		return nil, err
+	}
+	if err := rules.check(req.StartTime, req.EndTime, time.Now(), true); err != nil {
+		return nil, err
 	}
 
-	// 3) Проверка через Listing Service (убедиться, что listingID существует)
-	if err := s.checkListingExists(req.ListingID, req.AuthHeader); err != nil {
-		return nil, fmt.Errorf("listing validation failed: %w", err)
+	// 3) Проверка через User Service (убедиться, что гость и владелец существуют)
+	if err := s.checkUserExists(ctx, req.UserID, req.AuthHeader); err != nil {
+		return nil, fmt.Errorf("user validation failed: %w", err)
+	}
+	if err := s.checkUserExists(ctx, listing.OwnerID, req.AuthHeader); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrOwnerNotFound
+		}
+		return nil, fmt.Errorf("owner validation failed: %w", err)
 	}
 
-	// 4) Проверяем, нет ли пересечений в таблице bookings
-	overlap, err := s.repo.HasOverlap(ctx, req.ListingID, req.StartTime, req.EndTime)
+	// 4) Проверяем, нет ли пересечений в таблице bookings с учётом буферов на уборку между бронями
+	overlap, err := s.repo.HasOverlap(ctx, req.ListingID, req.StartTime, req.EndTime, rules.buffer)
 	if err != nil {
 		return nil, fmt.Errorf("error checking overlap: %w", err)
 	}
 	if overlap {
-		return nil, errors.New("listing is already booked for the given time range")
+		return nil, repository.ErrSlotTaken
 	}
 
-	// 5) Формируем объект Booking и сразу задаём Status = "PENDING"
+	// 5) Цена по текущим тарифам листинга фиксируется в брони
+	quote, err := s.quote(ctx, listing, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("pricing failed: %w", err)
+	}
+
+	// 6) Формируем объект Booking. Платная бронь сначала ждёт оплаты, бесплатная — сразу подтверждения владельца
+	status := model.StatusPending
+	if quote.Total > 0 {
+		status = model.StatusPendingPayment
+	}
 	booking := &model.Booking{
 		ListingID: req.ListingID,
 		UserID:    req.UserID,
-		OwnerID:   req.OwnerID,
+		OwnerID:   listing.OwnerID,
 		StartTime: req.StartTime,
 		EndTime:   req.EndTime,
-		Status:    "PENDING", // <-- Здесь задаём начальный статус
+		Status:    status, // <-- Здесь задаём начальный статус
+
+		TotalAmount: &quote.Total,
+		Currency:    &quote.Currency,
+		PriceItems:  quote.Items,
+
+		Buffer: rules.buffer,
 	}
+	applyGuests(booking, req)
 
-	// 6) Вставляем запись в БД
+	// 7) Вставляем запись в БД. Проверка выше — лишь быстрый отказ:
+	//    гонку двух параллельных запросов разрешает EXCLUDE-ограничение, и Create вернёт ErrSlotTaken.
 	if err := s.repo.Create(ctx, booking); err != nil {
 		return nil, fmt.Errorf("failed to create booking: %w", err)
 	}
 
+	// 8) Блокируем оплату; при мгновенной авторизации бронь сразу переходит в PENDING
+	if booking.Status == model.StatusPendingPayment {
+		return s.payments.start(ctx, booking)
+	}
 	return booking, nil
 }
 
-func (s *BookingService) GetBookingByID(ctx context.Context, id string) (*model.Booking, error) {
-	return s.repo.GetByID(ctx, id)
+// GetBookingByID возвращает бронь, если actor — её гость, владелец листинга или администратор.
+func (s *BookingService) GetBookingByID(ctx context.Context, id string, actor Actor) (*model.Booking, error) {
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanView(b) {
+		return nil, ErrForbidden
+	}
+	return b, nil
+}
+
+// GetBookingHistory возвращает журнал изменений брони. Смотреть его может владелец листинга или администратор.
+func (s *BookingService) GetBookingHistory(ctx context.Context, id string, actor Actor) ([]model.BookingAuditEntry, error) {
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsOwnerOf(b) {
+		return nil, ErrForbidden
+	}
+	return s.repo.ListHistory(ctx, id)
 }
 
-func (s *BookingService) ListBookingsByUser(ctx context.Context, userID string) ([]model.Booking, error) {
-	return s.repo.ListByUserID(ctx, userID)
+func (s *BookingService) ListBookingsByUser(ctx context.Context, userID string, f repository.ListFilter) (*repository.Page, error) {
+	return s.repo.ListByUserID(ctx, userID, f)
 }
 
+// ListBookingsByOwner возвращает брони по всем листингам владельца.
+// Смотреть их может только сам владелец или администратор.
+func (s *BookingService) ListBookingsByOwner(ctx context.Context, ownerID string, actor Actor, f repository.ListFilter) (*repository.Page, error) {
+	if !actor.Admin && actor.UserID != ownerID {
+		return nil, ErrForbidden
+	}
+	return s.repo.ListByOwnerID(ctx, ownerID, f)
+}
+
+// ListBookingsByListing возвращает ленту броней листинга. Смотреть её может только владелец
+// листинга (по данным listing-service) или администратор.
+func (s *BookingService) ListBookingsByListing(ctx context.Context, listingID string, actor Actor, f repository.ListFilter) (*repository.Page, error) {
+	if err := authorizeListingOwner(ctx, s.listings, listingID, actor); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByListingID(ctx, listingID, f)
+}
+
+// IsAvailableInterval сообщает, свободен ли листинг на [start, end) с учётом буферов между бронями.
+// Интервал, который нельзя забронировать по правилам листинга, недоступен.
 func (s *BookingService) IsAvailableInterval(ctx context.Context, listingID string, start, end time.Time) (bool, error) {
+	if !end.After(start) {
+		return false, ErrInvalidTimeRange
+	}
 	// Проверяем существование listing через Listing Service
-	if err := s.checkListingExists(listingID, ""); err != nil {
+	listing, err := fetchListing(ctx, s.listings, listingID, "")
+	if err != nil {
 		return false, fmt.Errorf("listing validation failed: %w", err)
 	}
+	rules, err := s.loadStayRules(ctx, listingID, listing)
+	if err != nil {
+		return false, err
+	}
+	if err := rules.check(start, end, time.Now(), true); err != nil {
+		return false, nil
+	}
 
-	overlap, err := s.repo.HasOverlap(ctx, listingID, start, end)
+	overlap, err := s.repo.HasOverlap(ctx, listingID, start, end, rules.buffer)
 	if err != nil {
 		return false, fmt.Errorf("error checking overlap: %w", err)
 	}
@@ -107,119 +217,87 @@ func (s *BookingService) IsAvailableInterval(ctx context.Context, listingID stri
 }
 
 func (s *BookingService) IsAvailableAtMoment(ctx context.Context, listingID string, timePoint time.Time) (bool, error) {
-	if err := s.checkListingExists(listingID, ""); err != nil {
+	if err := s.checkListingExists(ctx, listingID, ""); err != nil {
 		return false, fmt.Errorf("listing validation failed: %w", err)
 	}
 	return s.repo.IsAvailableAt(ctx, listingID, timePoint)
 }
 
-// checkUserExists запрашивает GET /api/users/{userID}
-func (s *BookingService) checkUserExists(userID, authHeader string) error {
-	url := fmt.Sprintf("%s/api/users/%s", s.userServiceURL, userID)
-	log.Printf("DEBUG: checkUserExists: URL=%s, Authorization=%q\n", url, authHeader)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return err
-	}
-	if authHeader != "" {
-		req.Header.Set("Authorization", authHeader)
-		log.Printf("DEBUG: checkUserExists: outgoing request Header Authorization=%q\n", req.Header.Get("Authorization"))
-	}
+// checkUserExists проверяет пользователя через user-service.
+func (s *BookingService) checkUserExists(ctx context.Context, userID, authHeader string) error {
+	_, err := s.users.GetUser(ctx, userID, authHeader)
+	return upstreamError(err, ErrUserNotFound)
+}
 
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
+// checkListingExists проверяет листинг через listing-service.
+func (s *BookingService) checkListingExists(ctx context.Context, listingID, authHeader string) error {
+	_, err := fetchListing(ctx, s.listings, listingID, authHeader)
+	return err
+}
 
-	log.Printf("DEBUG: checkUserExists: response status = %d\n", resp.StatusCode)
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("user-service returned status %d", resp.StatusCode)
+// upstreamError переводит ошибку клиента соседнего сервиса в ошибку сервисного слоя:
+// 404 — в notFound (ошибка валидации), всё остальное — в ErrUpstreamUnavailable (503).
+func upstreamError(err, notFound error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, clients.ErrNotFound):
+		return notFound
+	default:
+		return fmt.Errorf("%w: %v", ErrUpstreamUnavailable, err)
 	}
-	return nil
 }
 
-// checkListingExists запрашивает GET /api/listings/{listingID}
-func (s *BookingService) checkListingExists(listingID, authHeader string) error {
-	url := fmt.Sprintf("%s/api/listings/%s", s.listingServiceURL, listingID)
-	log.Printf("DEBUG: checkListingExists: URL=%s, Authorization=%q\n", url, authHeader)
-
-	req, err := http.NewRequest("GET", url, nil)
+// DailyAvailability возвращает слоты дня dateStr, нарезанные по расписанию листинга с учётом
+// закрытых и праздничных дней. День и часы работы считаются в часовом поясе листинга;
+// tz (необязательный) задаёт пояс, в котором отдаются start_local/end_local.
+func (s *BookingService) DailyAvailability(ctx context.Context, listingID, dateStr, tz string) (*DayAvailability, error) {
+	// 1. Расписание листинга (или расписание по умолчанию) и его часовой пояс.
+	sch, loc, displayLoc, err := s.listingCalendar(ctx, listingID, tz)
 	if err != nil {
-		return err
-	}
-	if authHeader != "" {
-		req.Header.Set("Authorization", authHeader)
-		log.Printf("DEBUG: checkListingExists: outgoing request Header Authorization=%q\n", req.Header.Get("Authorization"))
+		return nil, fmt.Errorf("DailyAvailability: %w", err)
 	}
 
-	resp, err := s.httpClient.Do(req)
+	// 2. Парсим dateStr как полночь календарного дня в поясе листинга.
+	date, err := time.ParseInLocation("2006-01-02", dateStr, loc)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidRange)
 	}
-	defer resp.Body.Close()
 
-	log.Printf("DEBUG: checkListingExists: response status = %d\n", resp.StatusCode)
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("listing-service returned status %d", resp.StatusCode)
-	}
-	return nil
-}
-func (s *BookingService) DailyAvailability(ctx context.Context, listingID, dateStr string) (map[string]bool, error) {
-	// 1. Парсим dateStr как дата без времени (формат “2006-01-02”).
-	date, err := time.Parse("2006-01-02", dateStr)
+	// 3. Закрытый/праздничный день, если он есть.
+	closures, err := s.schedules.ListClosures(ctx, listingID, dateStr, dateStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid date format: %w", err)
+		return nil, fmt.Errorf("DailyAvailability: %w", err)
+	}
+	var closure *model.ListingClosure
+	if len(closures) > 0 {
+		closure = &closures[0]
 	}
-	// Явно ставим UTC, чтобы не было смещения:
-	date = date.UTC()
 
-	// 2. Получаем все брони для этого listingID, которые хоть на секунду пересекаются с этим днём.
-	bookings, err := s.repo.ListByListingAndDate(ctx, listingID, date)
+	// 4. Правила бронирования: слоты раньше минимального срока до заезда, за горизонтом
+	//    или в запрещённый для заезда день недоступны.
+	rules, err := s.loadStayRules(ctx, listingID, nil)
 	if err != nil {
 		return nil, fmt.Errorf("DailyAvailability: %w", err)
 	}
 
-	// 3. Заранее заводим «часы» с 09 до 21 (или другие, по вашей логике).
-	hourMap := map[string]bool{}
-	for h := 9; h <= 21; h++ {
-		// форматируем: "09:00", "10:00" и т.д.
-		key := fmt.Sprintf("%02d:00", h)
-		hourMap[key] = true // по умолчанию все слоты считаем свободными
-	}
-
-	// 4. Теперь обходя все найденные брони, ставим занятые те часы, которые пересекаются
-	//    с каждым бронированием:
-	//
-	for _, b := range bookings {
-		// b.StartTime и b.EndTime — в UTC, потому что мы всё хранить в UTC.
-		// Находим час начала: например, 2025-02-22T11:30 UTC → заносим 11:00, 12:00 и т.д.
-		startHour := b.StartTime.Hour()
-		endHour := b.EndTime.Hour()
-		//
-		// Если бронирование началось, скажем, 11:30, мы считаем, что слот "11:00" занят
-		// (его полностью не поместить). Если закончится 14:00, слот "14:00" рехзнести?
-		// Часто считается, что интервал [start, end) значит, что конец не включает конечный час,
-		// т. е. если endTime == 14:00, то слот "14:00" считается свободным.
-		//
-		// Поэтому будем заносить занятыми слоты с startHour до (endHour-1) включительно:
-		for h := startHour; h < endHour; h++ {
-			key := fmt.Sprintf("%02d:00", h)
-			// если ключ есть в карте современных слотов (9..21), то помечаем false
-			if _, ok := hourMap[key]; ok {
-				hourMap[key] = false
-			}
-		}
+	// 5. Получаем все брони для этого listingID, которые вместе с буферами хоть на секунду задевают этот день.
+	//    Конец дня строится через time.Date: в день перехода на летнее/зимнее время он длится 23 или 25 часов.
+	y, m, d := date.Date()
+	from, to := rules.buffer.Around(date, time.Date(y, m, d+1, 0, 0, 0, 0, loc))
+	bookings, err := s.repo.ListByListingInRange(ctx, listingID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("DailyAvailability: %w", err)
 	}
 
-	// 5. Вернём итоговую карту
-	return hourMap, nil
+	// 6. Слот занят, если пересекается хотя бы с одной бронью с учётом буферов (интервалы полуоткрытые [start, end)).
+	day := buildDay(sch, closure, date, loc, displayLoc, bookings, rules, time.Now())
+	return &day, nil
 }
-func (s *BookingService) ListAllBookings(ctx context.Context) ([]model.Booking, error) {
-	bookings, err := s.repo.ListAllBookings(ctx)
+func (s *BookingService) ListAllBookings(ctx context.Context, f repository.ListFilter) (*repository.Page, error) {
+	page, err := s.repo.ListAllBookings(ctx, f)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching all bookings: %w", err)
 	}
-	return bookings, nil
+	return page, nil
 }
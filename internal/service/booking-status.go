@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"booking-service/internal/model"
+)
+
+// allowedTransitions описывает жизненный цикл брони: из какого статуса в какие можно перейти.
+// REJECTED, CANCELLED, COMPLETED и EXPIRED — финальные статусы. Переходы PENDING_PAYMENT → PENDING
+// и PENDING_PAYMENT → EXPIRED выполняет только PaymentService (оплата и истечение срока).
+var allowedTransitions = map[string][]string{
+	model.StatusPendingPayment: {model.StatusPending, model.StatusCancelled, model.StatusExpired},
+	model.StatusPending:        {model.StatusConfirmed, model.StatusRejected, model.StatusCancelled},
+	model.StatusConfirmed:      {model.StatusCancelled, model.StatusCompleted},
+}
+
+// CanTransition сообщает, разрешён ли переход from → to.
+func CanTransition(from, to string) bool {
+	for _, s := range allowedTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfirmBooking подтверждает бронь и списывает заблокированную оплату. Доступно владельцу листинга и администратору.
+func (s *BookingService) ConfirmBooking(ctx context.Context, bookingID string, actor Actor) (*model.Booking, error) {
+	return s.transition(ctx, bookingID, model.StatusConfirmed, func(b *model.Booking) error {
+		if !actor.IsOwnerOf(b) {
+			return ErrForbidden
+		}
+		return nil
+	})
+}
+
+// RejectBooking отклоняет бронь и снимает блокировку оплаты. Доступно владельцу листинга и администратору.
+func (s *BookingService) RejectBooking(ctx context.Context, bookingID string, actor Actor) (*model.Booking, error) {
+	return s.transition(ctx, bookingID, model.StatusRejected, func(b *model.Booking) error {
+		if !actor.IsOwnerOf(b) {
+			return ErrForbidden
+		}
+		return nil
+	})
+}
+
+// CompleteBooking помечает подтверждённую бронь завершённой. Доступно владельцу (или администратору)
+// после окончания брони.
+func (s *BookingService) CompleteBooking(ctx context.Context, bookingID string, actor Actor) (*model.Booking, error) {
+	return s.transition(ctx, bookingID, model.StatusCompleted, func(b *model.Booking) error {
+		if !actor.IsOwnerOf(b) {
+			return ErrForbidden
+		}
+		if time.Now().Before(b.EndTime) {
+			return fmt.Errorf("%w: booking has not ended yet", ErrInvalidTransition)
+		}
+		return nil
+	})
+}
+
+// transition загружает бронь, проверяет права через authorize и допустимость перехода,
+// а затем атомарно меняет статус в БД. Платёжные действия выполняются вокруг смены статуса:
+// списание — до неё (и возвращается, если статус поменять не удалось), возврат — после.
+func (s *BookingService) transition(
+	ctx context.Context,
+	bookingID, to string,
+	authorize func(b *model.Booking) error,
+) (*model.Booking, error) {
+	return s.transitionWith(ctx, bookingID, to, authorize, func(b *model.Booking) (*model.Booking, error) {
+		// Репозиторий ещё раз проверяет исходный статус внутри UPDATE,
+		// поэтому параллельная смена статуса не пройдёт незамеченной.
+		return s.repo.UpdateStatus(ctx, b.ID, []string{b.Status}, to)
+	})
+}
+
+// transitionWith — transition, в котором саму смену статуса выполняет update
+// (например, отмена записывает вместе со статусом причину и сумму возврата).
+func (s *BookingService) transitionWith(
+	ctx context.Context,
+	bookingID, to string,
+	authorize func(b *model.Booking) error,
+	update func(b *model.Booking) (*model.Booking, error),
+) (*model.Booking, error) {
+	b, err := s.repo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(b); err != nil {
+		return nil, err
+	}
+	if !CanTransition(b.Status, to) {
+		return nil, fmt.Errorf("%w: %s → %s", ErrInvalidTransition, b.Status, to)
+	}
+
+	if err := s.payments.beforeTransition(ctx, b, to); err != nil {
+		return nil, err
+	}
+
+	updated, err := update(b)
+	if err != nil {
+		s.payments.compensate(ctx, b, to)
+		return nil, err
+	}
+	s.payments.afterTransition(ctx, updated)
+	return updated, nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"booking-service/internal/model"
+)
+
+const maxCancellationReasonLen = 1000
+
+// RefundBreakdown — расчёт возврата при отмене брони по политике листинга.
+type RefundBreakdown struct {
+	Policy           string            `json:"policy"`
+	HoursBeforeStart float64           `json:"hours_before_start"` // отрицательное, если бронь уже началась
+	Tier             *model.RefundTier `json:"tier,omitempty"`     // применённая ступень; нет, если возврат не положен
+	PaymentCaptured  bool              `json:"payment_captured"`   // до подтверждения деньги только заблокированы и возвращаются полностью
+	RefundPercent    int               `json:"refund_percent"`
+	TotalAmount      int64             `json:"total_amount"`
+	RefundAmount     int64             `json:"refund_amount"`
+	RetainedAmount   int64             `json:"retained_amount"`
+	Currency         string            `json:"currency,omitempty"`
+}
+
+// CancellationResult — ответ на отмену брони: обновлённая бронь и расчёт возврата.
+type CancellationResult struct {
+	Booking *model.Booking  `json:"booking"`
+	Refund  RefundBreakdown `json:"refund"`
+}
+
+// CancelBooking отменяет бронь и возвращает оплату по политике отмены листинга. Доступно гостю,
+// который её создал, и администратору. Кто, когда и почему отменил бронь, записывается в неё.
+func (s *BookingService) CancelBooking(ctx context.Context, bookingID string, actor Actor, reason string) (*CancellationResult, error) {
+	reason = strings.TrimSpace(reason)
+	if len(reason) > maxCancellationReasonLen {
+		return nil, fmt.Errorf("%w: reason must be at most %d characters", ErrInvalidCancellation, maxCancellationReasonLen)
+	}
+
+	var refund *RefundBreakdown
+	authorize := func(b *model.Booking) error {
+		if !actor.IsGuestOf(b) {
+			return ErrForbidden
+		}
+		return nil
+	}
+	cancel := func(b *model.Booking) (*model.Booking, error) {
+		var err error
+		if refund, err = s.refundFor(ctx, b, time.Now()); err != nil {
+			return nil, err
+		}
+		c := model.Cancellation{CancelledBy: actor.UserID, RefundAmount: &refund.RefundAmount}
+		if reason != "" {
+			c.Reason = &reason
+		}
+		return s.repo.Cancel(ctx, b.ID, []string{b.Status}, c)
+	}
+
+	b, err := s.transitionWith(ctx, bookingID, model.StatusCancelled, authorize, cancel)
+	if err != nil {
+		return nil, err
+	}
+	return &CancellationResult{Booking: b, Refund: *refund}, nil
+}
+
+// PreviewCancellation считает, сколько вернётся гостю, если отменить бронь сейчас, ничего не меняя.
+func (s *BookingService) PreviewCancellation(ctx context.Context, bookingID string, actor Actor) (*RefundBreakdown, error) {
+	b, err := s.repo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanView(b) {
+		return nil, ErrForbidden
+	}
+	if !CanTransition(b.Status, model.StatusCancelled) {
+		return nil, fmt.Errorf("%w: %s → %s", ErrInvalidTransition, b.Status, model.StatusCancelled)
+	}
+	return s.refundFor(ctx, b, time.Now())
+}
+
+// refundFor считает возврат при отмене брони b в момент now.
+func (s *BookingService) refundFor(ctx context.Context, b *model.Booking, now time.Time) (*RefundBreakdown, error) {
+	policy, err := loadCancellationPolicy(ctx, s.policies, b.ListingID)
+	if err != nil {
+		return nil, fmt.Errorf("loading cancellation policy: %w", err)
+	}
+	return computeRefund(policy, b, now), nil
+}
+
+// computeRefund применяет политику к брони. Пока бронь не подтверждена, оплата только заблокирована,
+// и гость получает её обратно полностью. После подтверждения возвращается процент первой ступени,
+// до которой ещё не дошло время (ступени отсортированы по убыванию hours_before), с округлением вниз.
+func computeRefund(p *model.CancellationPolicy, b *model.Booking, now time.Time) *RefundBreakdown {
+	hours := b.StartTime.Sub(now).Hours()
+	r := &RefundBreakdown{
+		Policy:           p.Kind,
+		HoursBeforeStart: math.Round(hours*10) / 10,
+		PaymentCaptured:  b.Status == model.StatusConfirmed,
+	}
+	if b.TotalAmount != nil {
+		r.TotalAmount = *b.TotalAmount
+	}
+	if b.Currency != nil {
+		r.Currency = *b.Currency
+	}
+
+	if !r.PaymentCaptured {
+		r.RefundPercent = 100
+	} else {
+		for i := range p.Tiers {
+			if hours >= float64(p.Tiers[i].HoursBefore) {
+				tier := p.Tiers[i]
+				r.Tier = &tier
+				r.RefundPercent = tier.Percent
+				break
+			}
+		}
+	}
+	r.RefundAmount = r.TotalAmount * int64(r.RefundPercent) / 100
+	r.RetainedAmount = r.TotalAmount - r.RefundAmount
+	return r
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"booking-service/internal/model"
+)
+
+func TestComputeRefund(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	moderate := &model.CancellationPolicy{Kind: model.PolicyModerate, Tiers: policyPresets[model.PolicyModerate]}
+	strict := &model.CancellationPolicy{Kind: model.PolicyStrict, Tiers: policyPresets[model.PolicyStrict]}
+	custom := &model.CancellationPolicy{Kind: model.PolicyCustom, Tiers: model.RefundTiers{
+		{HoursBefore: 48, Percent: 90}, {HoursBefore: 0, Percent: 33},
+	}}
+
+	cases := []struct {
+		name     string
+		policy   *model.CancellationPolicy
+		status   string
+		before   time.Duration // сколько осталось до начала брони
+		total    int64
+		percent  int
+		tier     *model.RefundTier
+		refund   int64
+		retained int64
+	}{
+		{"unconfirmed booking is refunded in full", strict, model.StatusPending, time.Hour, 10000, 100, nil, 10000, 0},
+		{"unpaid booking is refunded in full", strict, model.StatusPendingPayment, -time.Hour, 10000, 100, nil, 10000, 0},
+		{"first tier", moderate, model.StatusConfirmed, 6 * 24 * time.Hour, 10000, 100, &model.RefundTier{HoursBefore: 120, Percent: 100}, 10000, 0},
+		{"exactly at the first tier", moderate, model.StatusConfirmed, 5 * 24 * time.Hour, 10000, 100, &model.RefundTier{HoursBefore: 120, Percent: 100}, 10000, 0},
+		{"just past the first tier", moderate, model.StatusConfirmed, 5*24*time.Hour - time.Second, 10000, 50, &model.RefundTier{HoursBefore: 24, Percent: 50}, 5000, 5000},
+		{"exactly at the last tier", moderate, model.StatusConfirmed, 24 * time.Hour, 10000, 50, &model.RefundTier{HoursBefore: 24, Percent: 50}, 5000, 5000},
+		{"past every tier", moderate, model.StatusConfirmed, 23 * time.Hour, 10000, 0, nil, 0, 10000},
+		{"booking already started", strict, model.StatusConfirmed, -2 * time.Hour, 10000, 0, nil, 0, 10000},
+		{"refund is rounded down", custom, model.StatusConfirmed, time.Hour, 1001, 33, &model.RefundTier{HoursBefore: 0, Percent: 33}, 330, 671},
+		{"zero-hour tier still applies at start", custom, model.StatusConfirmed, 0, 999, 33, &model.RefundTier{HoursBefore: 0, Percent: 33}, 329, 670},
+		{"odd amount at 50%", strict, model.StatusConfirmed, 7 * 24 * time.Hour, 12345, 50, &model.RefundTier{HoursBefore: 168, Percent: 50}, 6172, 6173},
+		{"free booking", strict, model.StatusConfirmed, 30 * 24 * time.Hour, 0, 100, &model.RefundTier{HoursBefore: 336, Percent: 100}, 0, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			currency := "USD"
+			b := &model.Booking{
+				Status:      c.status,
+				StartTime:   now.Add(c.before),
+				TotalAmount: &c.total,
+				Currency:    &currency,
+			}
+			r := computeRefund(c.policy, b, now)
+
+			if r.RefundPercent != c.percent || r.RefundAmount != c.refund || r.RetainedAmount != c.retained {
+				t.Fatalf("refund = %d%%, %d refunded, %d retained; want %d%%, %d, %d",
+					r.RefundPercent, r.RefundAmount, r.RetainedAmount, c.percent, c.refund, c.retained)
+			}
+			if (r.Tier == nil) != (c.tier == nil) || (r.Tier != nil && *r.Tier != *c.tier) {
+				t.Fatalf("tier = %+v, want %+v", r.Tier, c.tier)
+			}
+			if r.PaymentCaptured != (c.status == model.StatusConfirmed) {
+				t.Fatalf("payment captured = %v for %s", r.PaymentCaptured, c.status)
+			}
+			if r.Policy != c.policy.Kind || r.TotalAmount != c.total || r.Currency != currency {
+				t.Fatalf("breakdown = %+v", r)
+			}
+		})
+	}
+}
+
+func TestComputeRefundWithoutPrice(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	p := &model.CancellationPolicy{Kind: model.PolicyFlexible, Tiers: policyPresets[model.PolicyFlexible]}
+	b := &model.Booking{Status: model.StatusConfirmed, StartTime: now.Add(90 * time.Minute)}
+
+	r := computeRefund(p, b, now)
+	if r.TotalAmount != 0 || r.RefundAmount != 0 || r.RetainedAmount != 0 || r.Currency != "" {
+		t.Fatalf("breakdown for booking without price = %+v", r)
+	}
+	if r.HoursBeforeStart != 1.5 {
+		t.Fatalf("hours before start = %v, want 1.5", r.HoursBeforeStart)
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import "errors"
+
+// Ошибки сервисного слоя. Хендлеры сопоставляют их с HTTP-статусами и кодами ошибок API
+// через errors.Is, поэтому к ним можно добавлять контекст через fmt.Errorf("%w: ...").
+var (
+	// ErrForbidden — у вызывающего нет прав на операцию с бронью.
+	ErrForbidden = errors.New("forbidden")
+	// ErrInvalidTransition — переход между статусами брони недопустим.
+	ErrInvalidTransition = errors.New("invalid booking status transition")
+	// ErrInvalidTimeRange — end_time не позже start_time.
+	ErrInvalidTimeRange = errors.New("end_time must be after start_time")
+
+	// Нарушения правил бронирования листинга — у каждого свой код ошибки API.
+	ErrStartInPast          = errors.New("start_time is in the past")
+	ErrInsufficientNotice   = errors.New("not enough notice before check-in")
+	ErrBeyondHorizon        = errors.New("start_time is beyond the booking horizon")
+	ErrStayTooShort         = errors.New("stay is shorter than the listing minimum")
+	ErrStayTooLong          = errors.New("stay is longer than the listing maximum")
+	ErrCheckInDayNotAllowed = errors.New("check-in is not allowed on this weekday")
+	ErrCheckInTimeMismatch  = errors.New("start_time does not match the listing check-in time")
+	ErrCheckOutTimeMismatch = errors.New("end_time does not match the listing check-out time")
+
+	// ErrListingNotFound — listing-service не знает такого листинга.
+	ErrListingNotFound = errors.New("listing not found")
+	// ErrUserNotFound — user-service не знает такого пользователя.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrOwnerNotFound — user-service не знает владельца листинга.
+	ErrOwnerNotFound = errors.New("owner not found")
+	// ErrOwnerMismatch — owner_id в запросе не совпадает с владельцем листинга.
+	ErrOwnerMismatch = errors.New("owner_id does not match the listing owner")
+	// ErrInvalidGuests — некорректный состав гостей, пожелания или метаданные брони.
+	ErrInvalidGuests = errors.New("invalid guests")
+	// ErrCapacityExceeded — гостей больше, чем вмещает листинг.
+	ErrCapacityExceeded = errors.New("listing capacity exceeded")
+	// ErrPetsNotAllowed — листинг не принимает питомцев (или столько питомцев).
+	ErrPetsNotAllowed = errors.New("pets are not allowed")
+	// ErrPricingUnavailable — по тарифам листинга нельзя рассчитать цену (нет валюты, некорректные правила).
+	ErrPricingUnavailable = errors.New("listing pricing is not available")
+	// ErrUpstreamUnavailable — user-service или listing-service недоступен или ответил ошибкой.
+	ErrUpstreamUnavailable = errors.New("upstream service unavailable")
+
+	// ErrInvalidSchedule — расписание или закрытый день листинга заданы некорректно.
+	ErrInvalidSchedule = errors.New("invalid schedule")
+	// ErrInvalidTimeZone — неизвестный IANA-часовой пояс.
+	ErrInvalidTimeZone = errors.New("invalid time zone")
+	// ErrInvalidRange — некорректный диапазон дат для календаря доступности.
+	ErrInvalidRange = errors.New("invalid date range")
+
+	// ErrInvalidCancellation — некорректный запрос на отмену брони (например, слишком длинная причина).
+	ErrInvalidCancellation = errors.New("invalid cancellation request")
+	// ErrInvalidModification — изменение дат брони недопустимо (даты не изменились, бронь уже началась и т. п.).
+	ErrInvalidModification = errors.New("invalid booking modification")
+	// ErrInvalidBookingRules — некорректные правила бронирования листинга.
+	ErrInvalidBookingRules = errors.New("invalid booking rules")
+	// ErrInvalidPolicy — некорректная политика отмены листинга.
+	ErrInvalidPolicy = errors.New("invalid cancellation policy")
+
+	// ErrPaymentDeclined — платёжный провайдер отказал в блокировке или списании.
+	ErrPaymentDeclined = errors.New("payment declined")
+	// ErrInvalidPaymentWebhook — уведомление платёжного провайдера не прошло проверку.
+	ErrInvalidPaymentWebhook = errors.New("invalid payment webhook")
+
+	// ErrInvalidWebhook — некорректные настройки подписки на вебхуки.
+	ErrInvalidWebhook = errors.New("invalid webhook subscription")
+)
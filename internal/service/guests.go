@@ -0,0 +1,75 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"booking-service/internal/clients"
+	"booking-service/internal/model"
+)
+
+// Ограничения на данные о гостях в запросе на бронь.
+const (
+	maxGuestsPerKind       = 100
+	maxSpecialRequestsLen  = 2000
+	maxMetadataKeys        = 50
+	maxMetadataEncodedSize = 8 << 10 // 8 КБ JSON
+)
+
+// normalizeGuests проверяет состав гостей, пожелания и метаданные запроса, не заглядывая в листинг.
+// Без указанного числа взрослых бронь считается бронью на одного взрослого.
+func normalizeGuests(req *CreateBookingRequest) error {
+	if req.Adults == 0 {
+		req.Adults = 1
+	}
+	if req.Adults < 0 || req.Children < 0 || req.Pets < 0 {
+		return fmt.Errorf("%w: guest counts must not be negative", ErrInvalidGuests)
+	}
+	if req.Adults > maxGuestsPerKind || req.Children > maxGuestsPerKind || req.Pets > maxGuestsPerKind {
+		return fmt.Errorf("%w: at most %d guests of each kind", ErrInvalidGuests, maxGuestsPerKind)
+	}
+
+	req.SpecialRequests = strings.TrimSpace(req.SpecialRequests)
+	if len(req.SpecialRequests) > maxSpecialRequestsLen {
+		return fmt.Errorf("%w: special_requests must be at most %d characters", ErrInvalidGuests, maxSpecialRequestsLen)
+	}
+
+	if len(req.Metadata) > maxMetadataKeys {
+		return fmt.Errorf("%w: metadata may have at most %d keys", ErrInvalidGuests, maxMetadataKeys)
+	}
+	if req.Metadata != nil {
+		raw, err := json.Marshal(req.Metadata)
+		if err != nil || len(raw) > maxMetadataEncodedSize {
+			return fmt.Errorf("%w: metadata must be a JSON object of at most %d bytes", ErrInvalidGuests, maxMetadataEncodedSize)
+		}
+	}
+	return nil
+}
+
+// checkGuestPolicy сверяет состав гостей с вместимостью и правилами листинга о питомцах.
+func checkGuestPolicy(l *clients.Listing, adults, children, pets int) error {
+	if guests := adults + children; l.Capacity > 0 && guests > l.Capacity {
+		return fmt.Errorf("%w: %d guests requested, listing accommodates %d", ErrCapacityExceeded, guests, l.Capacity)
+	}
+	if pets > 0 && !l.PetsAllowed {
+		return ErrPetsNotAllowed
+	}
+	if pets > 0 && l.MaxPets > 0 && pets > l.MaxPets {
+		return fmt.Errorf("%w: %d pets requested, listing allows %d", ErrPetsNotAllowed, pets, l.MaxPets)
+	}
+	return nil
+}
+
+// applyGuests переносит проверенные данные о гостях из запроса в бронь.
+func applyGuests(b *model.Booking, req *CreateBookingRequest) {
+	b.Adults = req.Adults
+	b.Children = req.Children
+	b.Pets = req.Pets
+	if req.SpecialRequests != "" {
+		b.SpecialRequests = &req.SpecialRequests
+	}
+	if len(req.Metadata) > 0 {
+		b.Metadata = req.Metadata
+	}
+}
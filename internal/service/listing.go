@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"booking-service/internal/clients"
+	"booking-service/internal/pricing"
+)
+
+// fetchListing загружает листинг из listing-service, переводя ошибки клиента в ошибки сервисного слоя.
+func fetchListing(ctx context.Context, listings clients.ListingClient, listingID, authHeader string) (*clients.Listing, error) {
+	l, err := listings.GetListing(ctx, listingID, authHeader)
+	if err != nil {
+		return nil, upstreamError(err, ErrListingNotFound)
+	}
+	if l.OwnerID == "" {
+		return nil, fmt.Errorf("%w: listing-service returned listing %s without owner_id", ErrUpstreamUnavailable, listingID)
+	}
+	return l, nil
+}
+
+// authorizeListingOwner проверяет, что actor — владелец листинга по данным listing-service
+// или администратор. Листинг читается мимо кэша: после передачи листинга прежний владелец
+// не должен сохранять доступ ещё TTL кэша.
+func authorizeListingOwner(ctx context.Context, listings clients.ListingClient, listingID string, actor Actor) error {
+	if actor.Admin {
+		return nil
+	}
+	l, err := fetchListing(clients.NoCache(ctx), listings, listingID, "")
+	if err != nil {
+		return err
+	}
+	if l.OwnerID != actor.UserID {
+		return fmt.Errorf("%w: you are not the owner of this listing", ErrForbidden)
+	}
+	return nil
+}
+
+// pricingRules переводит тарифы листинга из listing-service в правила расчёта цены.
+func pricingRules(l *clients.Listing) pricing.Rules {
+	r := pricing.Rules{
+		Currency:    l.Currency,
+		Unit:        l.PriceUnit,
+		BaseRate:    pricing.ToMinor(l.Price),
+		WeekendRate: pricing.ToMinor(l.WeekendPrice),
+		CleaningFee: pricing.ToMinor(l.CleaningFee),
+		TaxRate:     l.TaxRate,
+	}
+	if r.Unit == "" {
+		r.Unit = pricing.UnitNight
+	}
+	for _, s := range l.SeasonalPrices {
+		r.Seasons = append(r.Seasons, pricing.Season{Name: s.Name, From: s.From, To: s.To, Rate: pricing.ToMinor(s.Price)})
+	}
+	for _, d := range l.LongStayDiscounts {
+		r.LongStay = append(r.LongStay, pricing.LongStayDiscount{MinUnits: d.MinUnits, Percent: d.Percent})
+	}
+	return r
+}
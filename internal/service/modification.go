@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"booking-service/internal/model"
+	"booking-service/internal/repository"
+)
+
+const maxModificationReasonLen = 1000
+
+// ModifyBookingRequest — новые даты брони для PATCH /bookings/{bookingID}. Нулевое время — граница не меняется.
+type ModifyBookingRequest struct {
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	Reason     string    `json:"reason,omitempty"`
+	AuthHeader string    `json:"-"` // Bearer <token>
+}
+
+// ModifyBooking переносит или продлевает бронь. Доступно гостю, который её создал, и администратору,
+// пока бронь в PENDING или CONFIRMED. Новый интервал проверяется на пересечения без учёта самой брони,
+// цена пересчитывается по текущим тарифам листинга.
+//
+// Ещё не подтверждённая бронь меняется сразу; если цена изменилась, под новую сумму делается новая
+// блокировка (пока она не авторизована, бронь ждёт в PENDING_PAYMENT). Подтверждённая бронь остаётся
+// CONFIRMED, только если новый интервал не выходит за прежний и цена не выросла (разница возвращается гостю);
+// иначе изменение требует повторного подтверждения владельцем: списанная сумма возвращается,
+// новая блокируется, и бронь возвращается в PENDING.
+func (s *BookingService) ModifyBooking(ctx context.Context, bookingID string, actor Actor, req *ModifyBookingRequest) (*model.Booking, error) {
+	// 1) Проверяем запрос
+	reason := strings.TrimSpace(req.Reason)
+	if len(reason) > maxModificationReasonLen {
+		return nil, fmt.Errorf("%w: reason must be at most %d characters", ErrInvalidModification, maxModificationReasonLen)
+	}
+
+	// 2) Бронь, права и статус
+	b, err := s.repo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsGuestOf(b) {
+		return nil, ErrForbidden
+	}
+	if b.Status != model.StatusPending && b.Status != model.StatusConfirmed {
+		return nil, fmt.Errorf("%w: a %s booking can't be modified", ErrInvalidTransition, b.Status)
+	}
+
+	// Не переданная граница остаётся прежней
+	if req.StartTime.IsZero() {
+		req.StartTime = b.StartTime
+	}
+	if req.EndTime.IsZero() {
+		req.EndTime = b.EndTime
+	}
+	if !req.EndTime.After(req.StartTime) {
+		return nil, ErrInvalidTimeRange
+	}
+	if req.StartTime.Equal(b.StartTime) && req.EndTime.Equal(b.EndTime) {
+		return nil, fmt.Errorf("%w: dates are unchanged", ErrInvalidModification)
+	}
+	now := time.Now()
+	if !b.StartTime.After(now) && !req.StartTime.Equal(b.StartTime) {
+		return nil, fmt.Errorf("%w: the booking has already started, only end_time can change", ErrInvalidModification)
+	}
+	if !req.EndTime.After(now) {
+		return nil, fmt.Errorf("%w: end_time must be in the future", ErrInvalidModification)
+	}
+
+	// 3) Правила бронирования листинга и пересечения с другими бронями
+	listing, err := fetchListing(ctx, s.listings, b.ListingID, req.AuthHeader)
+	if err != nil {
+		return nil, fmt.Errorf("listing validation failed: %w", err)
+	}
+	rules, err := s.loadStayRules(ctx, b.ListingID, listing)
+	if err != nil {
+		return nil, err
+	}
+	if err := rules.check(req.StartTime, req.EndTime, now, !req.StartTime.Equal(b.StartTime)); err != nil {
+		return nil, err
+	}
+
+	// Новый интервал не должен пересекаться с другими бронями листинга
+	overlap, err := s.repo.HasOverlapExcluding(ctx, b.ListingID, req.StartTime, req.EndTime, rules.buffer, b.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking overlap: %w", err)
+	}
+	if overlap {
+		return nil, repository.ErrSlotTaken
+	}
+
+	// 4) Цена по текущим тарифам листинга
+	quote, err := s.quote(ctx, listing, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("pricing failed: %w", err)
+	}
+	var oldTotal int64
+	if b.TotalAmount != nil {
+		oldTotal = *b.TotalAmount
+	}
+
+	// 5) Нужна ли повторная блокировка оплаты и повторное подтверждение владельцем
+	reapprove := b.Status == model.StatusConfirmed &&
+		(req.StartTime.Before(b.StartTime) || req.EndTime.After(b.EndTime) || quote.Total > oldTotal)
+	rehold := reapprove || (b.Status == model.StatusPending && quote.Total != oldTotal)
+
+	status := b.Status
+	var next *model.PaymentIntent
+	if rehold {
+		status = model.StatusPending
+		if quote.Total > 0 {
+			key := fmt.Sprintf("booking-%s-%d", b.ID, now.UnixNano())
+			if next, err = s.payments.hold(ctx, b.ID, quote.Total, quote.Currency, key); err != nil {
+				return nil, err
+			}
+			if next.Status != model.PaymentAuthorized {
+				status = model.StatusPendingPayment
+			}
+		}
+	}
+	prev, err := s.payments.intentOf(ctx, b.ID)
+	if err != nil {
+		if next != nil {
+			s.payments.discard(ctx, next)
+		}
+		return nil, err
+	}
+
+	// 6) Меняем бронь и пишем историю. Исходный статус проверяется ещё раз внутри UPDATE,
+	//    пересечения с другими бронями — EXCLUDE-ограничением.
+	changed := *b
+	changed.StartTime = req.StartTime
+	changed.EndTime = req.EndTime
+	changed.Buffer = rules.buffer
+	changed.Status = status
+	changed.TotalAmount = &quote.Total
+	changed.Currency = &quote.Currency
+	changed.PriceItems = quote.Items
+
+	m := &model.BookingModification{
+		BookingID:    b.ID,
+		ModifiedBy:   actor.UserID,
+		OldStartTime: b.StartTime,
+		OldEndTime:   b.EndTime,
+		NewStartTime: req.StartTime,
+		NewEndTime:   req.EndTime,
+		OldTotal:     b.TotalAmount,
+		NewTotal:     &quote.Total,
+		OldStatus:    b.Status,
+		NewStatus:    status,
+	}
+	if reason != "" {
+		m.Reason = &reason
+	}
+
+	updated, err := s.repo.Reschedule(ctx, &changed, b.Status, m)
+	if err != nil {
+		if next != nil {
+			s.payments.discard(ctx, next)
+		}
+		return nil, fmt.Errorf("failed to modify booking: %w", err)
+	}
+
+	// 7) Новая блокировка сохраняется, прежняя снимается; при снижении цены разница возвращается
+	s.payments.afterModification(ctx, prev, next, updated)
+	return updated, nil
+}
+
+// ListModifications возвращает историю изменений брони, если actor может её видеть.
+func (s *BookingService) ListModifications(ctx context.Context, bookingID string, actor Actor) ([]model.BookingModification, error) {
+	b, err := s.repo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanView(b) {
+		return nil, ErrForbidden
+	}
+	return s.repo.ListModifications(ctx, bookingID)
+}
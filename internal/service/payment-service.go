@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"booking-service/internal/audit"
+	"booking-service/internal/model"
+	"booking-service/internal/payments"
+	"booking-service/internal/repository"
+)
+
+const expiryBatchSize = 100
+
+// PaymentService ведёт оплату броней по схеме hold/capture:
+//   - при создании платной брони сумма блокируется (Authorize), бронь ждёт в PENDING_PAYMENT;
+//   - после авторизации (сразу или по webhook провайдера) бронь переходит в PENDING;
+//   - подтверждение владельцем списывает сумму (Capture), отказ и отмена снимают блокировку (Void)
+//     или возвращают деньги (Refund);
+//   - неоплаченные вовремя брони истекают и освобождают слот.
+type PaymentService struct {
+	bookings *repository.BookingRepository
+	repo     *repository.PaymentRepository
+	provider payments.PaymentProvider
+}
+
+func NewPaymentService(
+	bookings *repository.BookingRepository,
+	repo *repository.PaymentRepository,
+	provider payments.PaymentProvider,
+) *PaymentService {
+	return &PaymentService{bookings: bookings, repo: repo, provider: provider}
+}
+
+// GetPayment возвращает платёж по брони, если actor может её видеть.
+func (s *PaymentService) GetPayment(ctx context.Context, bookingID string, actor Actor) (*model.PaymentIntent, error) {
+	b, err := s.bookings.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanView(b) {
+		return nil, ErrForbidden
+	}
+	p, err := s.repo.GetByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsGuestOf(b) {
+		p.ClientSecret = nil // секрет нужен только гостю для завершения оплаты
+	}
+	return p, nil
+}
+
+// start блокирует стоимость только что созданной брони в PENDING_PAYMENT.
+// Если провайдер отказал или платёж не удалось сохранить, блокировка снимается, а бронь отменяется,
+// чтобы не держать ни слот, ни деньги гостя.
+func (s *PaymentService) start(ctx context.Context, b *model.Booking) (*model.Booking, error) {
+	intent, err := s.hold(ctx, b.ID, *b.TotalAmount, *b.Currency, "booking-"+b.ID)
+	if err != nil {
+		s.abandon(ctx, b.ID)
+		return nil, err
+	}
+	if err := s.repo.CreateIntent(ctx, intent); err != nil {
+		// Без записи о платеже блокировку потом никто не снимет — снимаем сразу
+		s.discard(context.WithoutCancel(ctx), intent)
+		s.abandon(ctx, b.ID)
+		return nil, err
+	}
+
+	if intent.Status != model.PaymentAuthorized {
+		return b, nil
+	}
+	return s.bookings.UpdateStatus(ctx, b.ID, []string{model.StatusPendingPayment}, model.StatusPending)
+}
+
+// abandon отменяет бронь, оплату которой не удалось начать. Отмена не зависит от отмены
+// запроса клиентом: иначе бронь держала бы слот до истечения срока оплаты.
+func (s *PaymentService) abandon(ctx context.Context, bookingID string) {
+	ctx = context.WithoutCancel(ctx)
+	if _, err := s.bookings.UpdateStatus(ctx, bookingID, []string{model.StatusPendingPayment}, model.StatusCancelled); err != nil {
+		log.Printf("payments: releasing booking %s after failed payment start: %v\n", bookingID, err)
+	}
+}
+
+// hold блокирует amount у провайдера и возвращает ещё не сохранённый платёж брони bookingID.
+// Если провайдер авторизовал платёж сразу, платёж возвращается в статусе AUTHORIZED.
+func (s *PaymentService) hold(ctx context.Context, bookingID string, amount int64, currency, idempotencyKey string) (*model.PaymentIntent, error) {
+	pay, err := s.provider.Authorize(ctx, payments.AuthorizeRequest{
+		BookingID:      bookingID,
+		Amount:         amount,
+		Currency:       currency,
+		IdempotencyKey: idempotencyKey,
+	})
+	if err != nil {
+		if errors.Is(err, payments.ErrDeclined) {
+			return nil, fmt.Errorf("%w: %v", ErrPaymentDeclined, err)
+		}
+		return nil, fmt.Errorf("%w: payment provider: %v", ErrUpstreamUnavailable, err)
+	}
+
+	intent := &model.PaymentIntent{
+		BookingID:         bookingID,
+		Provider:          s.provider.Name(),
+		ProviderPaymentID: pay.ID,
+		Amount:            amount,
+		Currency:          currency,
+		Status:            model.PaymentRequiresAuthorization,
+	}
+	if pay.ClientSecret != "" {
+		intent.ClientSecret = &pay.ClientSecret
+	}
+	if pay.Status == payments.StatusAuthorized {
+		intent.Status = model.PaymentAuthorized
+	}
+	return intent, nil
+}
+
+// HandleWebhook обрабатывает уведомление провайдера. Повторная доставка того же события безопасна.
+func (s *PaymentService) HandleWebhook(ctx context.Context, header http.Header, body []byte) error {
+	ctx = audit.WithActor(ctx, audit.ActorPaymentProvider)
+	ev, err := s.provider.ParseWebhook(header, body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPaymentWebhook, err)
+	}
+	intent, err := s.repo.GetByProviderPaymentID(ctx, s.provider.Name(), ev.PaymentID)
+	if err != nil {
+		return err
+	}
+	if intent.Status != model.PaymentRequiresAuthorization {
+		pending, err := s.awaitsWebhook(ctx, intent, ev.Type)
+		if err != nil || !pending {
+			return err // уже обработано
+		}
+	}
+
+	switch ev.Type {
+	case payments.EventAuthorized:
+		if intent.Status != model.PaymentAuthorized {
+			if err := s.repo.UpdateStatus(ctx, intent.ID, model.PaymentAuthorized, nil); err != nil {
+				return err
+			}
+		}
+		_, err := s.bookings.UpdateStatus(ctx, intent.BookingID, []string{model.StatusPendingPayment}, model.StatusPending)
+		if errors.Is(err, repository.ErrStatusConflict) {
+			// Бронь успела истечь или быть отменённой — деньги держать незачем
+			intent.Status = model.PaymentAuthorized
+			s.release(ctx, intent, nil)
+			return nil
+		}
+		return err
+
+	case payments.EventFailed:
+		if intent.Status != model.PaymentFailed {
+			reason := ev.Reason
+			if err := s.repo.UpdateStatus(ctx, intent.ID, model.PaymentFailed, &reason); err != nil {
+				return err
+			}
+		}
+		_, err := s.bookings.UpdateStatus(ctx, intent.BookingID, []string{model.StatusPendingPayment}, model.StatusCancelled)
+		if errors.Is(err, repository.ErrStatusConflict) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// awaitsWebhook сообщает, что повтор события eventType по уже обработанному платежу нужно довести
+// до конца. Статус платежа и статус брони меняются разными запросами: если обработка упала между ними,
+// платёж уже в итоговом статусе, а бронь всё ещё ждёт оплаты — провайдер повторит вебхук, и переход
+// брони надо выполнить. Событие по платежу, который уже заменён новым (после изменения брони), не применяется.
+func (s *PaymentService) awaitsWebhook(ctx context.Context, intent *model.PaymentIntent, eventType string) (bool, error) {
+	switch {
+	case eventType == payments.EventAuthorized && intent.Status == model.PaymentAuthorized:
+	case eventType == payments.EventFailed && intent.Status == model.PaymentFailed:
+	default:
+		return false, nil
+	}
+	current, err := s.intentOf(ctx, intent.BookingID)
+	if err != nil || current == nil || current.ID != intent.ID {
+		return false, err
+	}
+	b, err := s.bookings.GetByID(ctx, intent.BookingID)
+	if err != nil {
+		return false, err
+	}
+	return b.Status == model.StatusPendingPayment, nil
+}
+
+// beforeTransition выполняет платёжные действия, которые должны пройти до смены статуса брони:
+// подтверждение списывает заблокированную сумму.
+func (s *PaymentService) beforeTransition(ctx context.Context, b *model.Booking, to string) error {
+	if to != model.StatusConfirmed {
+		return nil
+	}
+	intent, err := s.intentOf(ctx, b.ID)
+	if err != nil || intent == nil || intent.Status != model.PaymentAuthorized {
+		return err
+	}
+	if err := s.provider.Capture(ctx, intent.ProviderPaymentID, intent.Amount); err != nil {
+		if errors.Is(err, payments.ErrDeclined) {
+			return fmt.Errorf("%w: %v", ErrPaymentDeclined, err)
+		}
+		return fmt.Errorf("%w: payment provider: %v", ErrUpstreamUnavailable, err)
+	}
+	return s.repo.UpdateStatus(ctx, intent.ID, model.PaymentCaptured, nil)
+}
+
+// afterTransition выполняет платёжные действия после смены статуса: при отказе, отмене
+// или истечении брони блокировка снимается, а списанная сумма возвращается — при отмене
+// не больше refund_amount, рассчитанного по политике отмены листинга.
+// Сбои провайдера не отменяют смену статуса — они записываются в last_error платежа.
+func (s *PaymentService) afterTransition(ctx context.Context, b *model.Booking) {
+	switch b.Status {
+	case model.StatusRejected, model.StatusCancelled, model.StatusExpired:
+	default:
+		return
+	}
+	intent, err := s.intentOf(ctx, b.ID)
+	if err != nil {
+		log.Printf("payments: booking %s: %v\n", b.ID, err)
+		return
+	}
+	if intent != nil {
+		s.release(ctx, intent, b.RefundAmount)
+	}
+}
+
+// discard снимает блокировку, полученную через hold, если изменить бронь так и не удалось.
+func (s *PaymentService) discard(ctx context.Context, intent *model.PaymentIntent) {
+	if err := s.provider.Void(ctx, intent.ProviderPaymentID); err != nil {
+		log.Printf("payments: voiding unused hold %s for booking %s: %v\n", intent.ProviderPaymentID, intent.BookingID, err)
+	}
+}
+
+// afterModification приводит платежи в соответствие с изменённой бронью. Если под новую цену
+// была сделана новая блокировка (next), она сохраняется, а прежний платёж prev снимается или
+// возвращается полностью. Иначе, если цена подтверждённой брони снизилась, гостю возвращается разница,
+// а ставшая ненужной блокировка неподтверждённой брони снимается.
+func (s *PaymentService) afterModification(ctx context.Context, prev, next *model.PaymentIntent, b *model.Booking) {
+	if next != nil {
+		if err := s.repo.CreateIntent(ctx, next); err != nil {
+			log.Printf("payments: booking %s: %v\n", b.ID, err)
+			s.discard(ctx, next)
+			return
+		}
+		if prev != nil {
+			s.release(ctx, prev, nil)
+		}
+		return
+	}
+	if prev == nil {
+		return
+	}
+	var total int64
+	if b.TotalAmount != nil {
+		total = *b.TotalAmount
+	}
+	switch prev.Status {
+	case model.PaymentRequiresAuthorization, model.PaymentAuthorized:
+		if prev.Amount != total {
+			s.release(ctx, prev, nil) // бронь стала бесплатной — блокировка больше не нужна
+		}
+	case model.PaymentCaptured:
+		if diff := prev.Amount - prev.RefundedAmount - total; diff > 0 {
+			s.release(ctx, prev, &diff)
+		}
+	}
+}
+
+// compensate возвращает деньги, если beforeTransition успел списать их, а смена статуса не удалась.
+func (s *PaymentService) compensate(ctx context.Context, b *model.Booking, to string) {
+	if to != model.StatusConfirmed {
+		return
+	}
+	intent, err := s.intentOf(ctx, b.ID)
+	if err == nil && intent != nil && intent.Status == model.PaymentCaptured {
+		s.release(ctx, intent, nil)
+	}
+}
+
+// release снимает блокировку или возвращает ещё не возвращённую часть списанной суммы.
+// amount, если задан, — сколько вернуть из списанного (не больше ещё не возвращённого остатка).
+func (s *PaymentService) release(ctx context.Context, intent *model.PaymentIntent, amount *int64) {
+	var err error
+	switch intent.Status {
+	case model.PaymentRequiresAuthorization, model.PaymentAuthorized:
+		if err = s.provider.Void(ctx, intent.ProviderPaymentID); err == nil {
+			err = s.repo.UpdateStatus(ctx, intent.ID, model.PaymentVoided, nil)
+		}
+	case model.PaymentCaptured:
+		refund := intent.Amount - intent.RefundedAmount
+		if amount != nil && *amount < refund {
+			refund = *amount
+		}
+		if refund <= 0 {
+			return
+		}
+		if err = s.provider.Refund(ctx, intent.ProviderPaymentID, refund); err == nil {
+			err = s.repo.AddRefund(ctx, intent.ID, refund)
+		}
+	default:
+		return
+	}
+	if err != nil {
+		log.Printf("payments: releasing payment %s: %v\n", intent.ID, err)
+		msg := err.Error()
+		if updErr := s.repo.UpdateStatus(ctx, intent.ID, intent.Status, &msg); updErr != nil {
+			log.Printf("payments: %v\n", updErr)
+		}
+	}
+}
+
+// intentOf возвращает платёж брони или nil, если бронь бесплатная (платежа нет).
+func (s *PaymentService) intentOf(ctx context.Context, bookingID string) (*model.PaymentIntent, error) {
+	intent, err := s.repo.GetByBookingID(ctx, bookingID)
+	if errors.Is(err, repository.ErrPaymentNotFound) {
+		return nil, nil
+	}
+	return intent, err
+}
+
+// ExpireUnpaid переводит в EXPIRED брони, не оплаченные за ttl, и снимает их блокировки.
+func (s *PaymentService) ExpireUnpaid(ctx context.Context, ttl time.Duration) (int, error) {
+	total := 0
+	for {
+		expired, err := s.bookings.ExpireUnpaid(ctx, time.Now().Add(-ttl), expiryBatchSize)
+		if err != nil {
+			return total, err
+		}
+		for i := range expired {
+			s.afterTransition(ctx, &expired[i])
+		}
+		total += len(expired)
+		if len(expired) < expiryBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// RunExpiry каждые interval истекает неоплаченные брони, пока не отменён ctx.
+func (s *PaymentService) RunExpiry(ctx context.Context, ttl, interval time.Duration) {
+	ctx = audit.WithActor(ctx, audit.ActorSystem)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.ExpireUnpaid(ctx, ttl)
+			if err != nil {
+				log.Printf("payments: expiring unpaid bookings: %v\n", err)
+			}
+			if n > 0 {
+				log.Printf("payments: expired %d unpaid bookings\n", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"booking-service/internal/clients"
+	"booking-service/internal/model"
+	"booking-service/internal/repository"
+)
+
+// Ограничения на собственные (CUSTOM) ступени политики отмены.
+const (
+	maxRefundTiers     = 10
+	maxTierHoursBefore = 365 * 24
+)
+
+// defaultPolicyKind — политика отмены листингов, для которых владелец её не выбрал.
+const defaultPolicyKind = model.PolicyFlexible
+
+// policyPresets — ступени возврата пресетов по убыванию hours_before:
+//   - FLEXIBLE: полный возврат при отмене не позже чем за сутки до начала;
+//   - MODERATE: полный возврат за 5 дней, половина — за сутки;
+//   - STRICT: полный возврат за 14 дней, половина — за 7 дней.
+var policyPresets = map[string]model.RefundTiers{
+	model.PolicyFlexible: {{HoursBefore: 24, Percent: 100}},
+	model.PolicyModerate: {{HoursBefore: 5 * 24, Percent: 100}, {HoursBefore: 24, Percent: 50}},
+	model.PolicyStrict:   {{HoursBefore: 14 * 24, Percent: 100}, {HoursBefore: 7 * 24, Percent: 50}},
+}
+
+type PolicyService struct {
+	repo     *repository.PolicyRepository
+	listings clients.ListingClient
+}
+
+func NewPolicyService(repo *repository.PolicyRepository, listings clients.ListingClient) *PolicyService {
+	return &PolicyService{repo: repo, listings: listings}
+}
+
+// GetCancellationPolicy возвращает политику отмены листинга или политику по умолчанию, если она не задана.
+func (s *PolicyService) GetCancellationPolicy(ctx context.Context, listingID string) (*model.CancellationPolicy, error) {
+	return loadCancellationPolicy(ctx, s.repo, listingID)
+}
+
+// SaveCancellationPolicy проверяет и сохраняет политику отмены листинга.
+// Менять её может владелец листинга или администратор.
+func (s *PolicyService) SaveCancellationPolicy(ctx context.Context, p *model.CancellationPolicy, actor Actor) error {
+	if err := authorizeListingOwner(ctx, s.listings, p.ListingID, actor); err != nil {
+		return err
+	}
+	if err := normalizePolicy(p); err != nil {
+		return err
+	}
+	return s.repo.UpsertCancellationPolicy(ctx, p)
+}
+
+// DeleteCancellationPolicy сбрасывает политику отмены листинга на политику по умолчанию.
+func (s *PolicyService) DeleteCancellationPolicy(ctx context.Context, listingID string, actor Actor) error {
+	if err := authorizeListingOwner(ctx, s.listings, listingID, actor); err != nil {
+		return err
+	}
+	return s.repo.DeleteCancellationPolicy(ctx, listingID)
+}
+
+func loadCancellationPolicy(ctx context.Context, repo *repository.PolicyRepository, listingID string) (*model.CancellationPolicy, error) {
+	p, err := repo.GetCancellationPolicy(ctx, listingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &model.CancellationPolicy{
+			ListingID: listingID,
+			Kind:      defaultPolicyKind,
+			Tiers:     policyPresets[defaultPolicyKind],
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// normalizePolicy проверяет политику и приводит её ступени к каноническому виду:
+// у пресетов они подставляются из policyPresets, собственные сортируются по убыванию hours_before.
+func normalizePolicy(p *model.CancellationPolicy) error {
+	if preset, ok := policyPresets[p.Kind]; ok {
+		if len(p.Tiers) > 0 {
+			return fmt.Errorf("%w: tiers can only be set for %s policy", ErrInvalidPolicy, model.PolicyCustom)
+		}
+		p.Tiers = preset
+		return nil
+	}
+	if p.Kind != model.PolicyCustom {
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidPolicy, p.Kind)
+	}
+
+	if len(p.Tiers) == 0 || len(p.Tiers) > maxRefundTiers {
+		return fmt.Errorf("%w: custom policy needs 1 to %d tiers", ErrInvalidPolicy, maxRefundTiers)
+	}
+	seen := make(map[int]bool, len(p.Tiers))
+	for _, t := range p.Tiers {
+		if t.HoursBefore < 0 || t.HoursBefore > maxTierHoursBefore {
+			return fmt.Errorf("%w: hours_before must be between 0 and %d", ErrInvalidPolicy, maxTierHoursBefore)
+		}
+		if t.Percent < 0 || t.Percent > 100 {
+			return fmt.Errorf("%w: percent must be between 0 and 100", ErrInvalidPolicy)
+		}
+		if seen[t.HoursBefore] {
+			return fmt.Errorf("%w: duplicate tier for %d hours", ErrInvalidPolicy, t.HoursBefore)
+		}
+		seen[t.HoursBefore] = true
+	}
+	sort.Slice(p.Tiers, func(i, j int) bool { return p.Tiers[i].HoursBefore > p.Tiers[j].HoursBefore })
+	return nil
+}
+
+// GetBookingRules возвращает правила бронирования листинга (нулевые значения — правила по умолчанию).
+func (s *PolicyService) GetBookingRules(ctx context.Context, listingID string) (*model.BookingRules, error) {
+	return loadBookingRules(ctx, s.repo, listingID)
+}
+
+// SaveBookingRules проверяет и сохраняет правила бронирования листинга.
+// Менять их может владелец листинга или администратор.
+func (s *PolicyService) SaveBookingRules(ctx context.Context, br *model.BookingRules, actor Actor) error {
+	if err := authorizeListingOwner(ctx, s.listings, br.ListingID, actor); err != nil {
+		return err
+	}
+	if err := validateBookingRules(br); err != nil {
+		return err
+	}
+	return s.repo.UpsertBookingRules(ctx, br)
+}
+
+// DeleteBookingRules сбрасывает правила бронирования листинга на правила по умолчанию.
+func (s *PolicyService) DeleteBookingRules(ctx context.Context, listingID string, actor Actor) error {
+	if err := authorizeListingOwner(ctx, s.listings, listingID, actor); err != nil {
+		return err
+	}
+	return s.repo.DeleteBookingRules(ctx, listingID)
+}
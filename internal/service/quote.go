@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"booking-service/internal/clients"
+	"booking-service/internal/pricing"
+)
+
+// QuoteBooking рассчитывает стоимость брони листинга на [start, end) по текущим тарифам listing-service.
+// Даты проверяются по правилам бронирования листинга так же, как при создании брони.
+func (s *BookingService) QuoteBooking(ctx context.Context, listingID string, start, end time.Time, authHeader string) (*pricing.Quote, error) {
+	if !end.After(start) {
+		return nil, ErrInvalidTimeRange
+	}
+	listing, err := fetchListing(ctx, s.listings, listingID, authHeader)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.loadStayRules(ctx, listingID, listing)
+	if err != nil {
+		return nil, err
+	}
+	if err := rules.check(start, end, time.Now(), true); err != nil {
+		return nil, err
+	}
+	return s.quote(ctx, listing, start, end)
+}
+
+// quote считает цену по тарифам листинга в его часовом поясе (из расписания листинга).
+// Бронь длиннее maxStayDays не считается: почасовой тариф раскладывает интервал на часы в памяти.
+func (s *BookingService) quote(ctx context.Context, listing *clients.Listing, start, end time.Time) (*pricing.Quote, error) {
+	if end.Sub(start) > maxStayDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: maximum stay is %d days", ErrStayTooLong, maxStayDays)
+	}
+	_, loc, _, err := s.listingCalendar(ctx, listing.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	q, err := pricing.Calculate(pricingRules(listing), start, end, loc)
+	if errors.Is(err, pricing.ErrInvalidRules) {
+		return nil, fmt.Errorf("%w: %v", ErrPricingUnavailable, err)
+	}
+	return q, err
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"booking-service/internal/clients"
+	"booking-service/internal/model"
+	"booking-service/internal/pricing"
+	"booking-service/internal/repository"
+)
+
+// Пределы для настроек владельца.
+const (
+	maxHorizonDays   = 3 * 365
+	maxRuleMinutes   = 365 * 24 * 60
+	maxBufferMinutes = 7 * 24 * 60
+	// maxStayDays — предел длительности любой брони независимо от правил владельца
+	maxStayDays = maxRuleMinutes / (24 * 60)
+)
+
+// stayRules — действующие правила бронирования листинга: настройки владельца (model.BookingRules),
+// дополненные ограничениями из listing-service (min_stay/max_stay в ночах). Незаданные правила не ограничивают бронь.
+type stayRules struct {
+	loc         *time.Location // часовой пояс листинга: в нём считаются ночи, дни и время заезда
+	minDuration time.Duration  // 0 — без ограничения
+	maxDuration time.Duration  // 0 — без ограничения
+	minNights   int            // 0 — без ограничения
+	maxNights   int            // 0 — без ограничения
+	minNotice   time.Duration
+	horizon     time.Duration         // 0 — без ограничения
+	weekdays    map[time.Weekday]bool // пусто — заезд в любой день
+	checkIn     string                // "HH:MM"; пусто — в любое время
+	checkOut    string
+	buffer      model.Buffer // фиксируется в новой брони; учитывается при проверке пересечений
+}
+
+// loadStayRules собирает правила листинга. listing может быть nil (календарь доступности):
+// тогда ограничения listing-service в ночах не учитываются.
+func (s *BookingService) loadStayRules(ctx context.Context, listingID string, listing *clients.Listing) (*stayRules, error) {
+	sch, err := loadSchedule(ctx, s.schedules, listingID)
+	if err != nil {
+		return nil, fmt.Errorf("listing schedule: %w", err)
+	}
+	loc, err := loadLocation(sch.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("listing schedule: %w", err)
+	}
+	br, err := loadBookingRules(ctx, s.policies, listingID)
+	if err != nil {
+		return nil, fmt.Errorf("booking rules: %w", err)
+	}
+
+	r := &stayRules{
+		loc:         loc,
+		minDuration: time.Duration(br.MinDurationMinutes) * time.Minute,
+		maxDuration: time.Duration(br.MaxDurationMinutes) * time.Minute,
+		minNotice:   time.Duration(br.MinNoticeMinutes) * time.Minute,
+		horizon:     time.Duration(br.MaxHorizonDays) * 24 * time.Hour,
+		weekdays:    map[time.Weekday]bool{},
+		buffer:      br.Buffer,
+	}
+	for _, d := range br.CheckInWeekdays {
+		r.weekdays[time.Weekday(d)] = true
+	}
+	if br.CheckInTime != nil {
+		r.checkIn = *br.CheckInTime
+	}
+	if br.CheckOutTime != nil {
+		r.checkOut = *br.CheckOutTime
+	}
+	if listing != nil && (listing.PriceUnit == "" || listing.PriceUnit == pricing.UnitNight) {
+		r.minNights, r.maxNights = listing.MinStay, listing.MaxStay
+	}
+	return r, nil
+}
+
+// check проверяет интервал брони [start, end) на момент now. Проверки заезда (срок, горизонт,
+// день и время) пропускаются, если начало брони не меняется (при изменении брони переносится только выезд).
+func (r *stayRules) check(start, end, now time.Time, startChanged bool) error {
+	if startChanged {
+		if start.Before(now) {
+			return ErrStartInPast
+		}
+		if start.Before(now.Add(r.minNotice)) {
+			return fmt.Errorf("%w: book at least %s before check-in", ErrInsufficientNotice, r.minNotice)
+		}
+		if r.horizon > 0 && start.After(now.Add(r.horizon)) {
+			return fmt.Errorf("%w: bookings open %d days ahead", ErrBeyondHorizon, int(r.horizon.Hours()/24))
+		}
+	}
+
+	d := end.Sub(start)
+	if d < r.minDuration {
+		return fmt.Errorf("%w: minimum stay is %s", ErrStayTooShort, r.minDuration)
+	}
+	if r.maxDuration > 0 && d > r.maxDuration {
+		return fmt.Errorf("%w: maximum stay is %s", ErrStayTooLong, r.maxDuration)
+	}
+	nights := calendarNights(start.In(r.loc), end.In(r.loc))
+	if r.minNights > 0 && nights < r.minNights {
+		return fmt.Errorf("%w: minimum stay is %d nights", ErrStayTooShort, r.minNights)
+	}
+	if r.maxNights > 0 && nights > r.maxNights {
+		return fmt.Errorf("%w: maximum stay is %d nights", ErrStayTooLong, r.maxNights)
+	}
+
+	localStart, localEnd := start.In(r.loc), end.In(r.loc)
+	if startChanged && len(r.weekdays) > 0 && !r.weekdays[localStart.Weekday()] {
+		return fmt.Errorf("%w: check-in on %s is not allowed", ErrCheckInDayNotAllowed, localStart.Weekday())
+	}
+	if startChanged && r.checkIn != "" && localStart.Format("15:04") != r.checkIn {
+		return fmt.Errorf("%w: check-in is at %s (%s)", ErrCheckInTimeMismatch, r.checkIn, r.loc)
+	}
+	if r.checkOut != "" && localEnd.Format("15:04") != r.checkOut {
+		return fmt.Errorf("%w: check-out is at %s (%s)", ErrCheckOutTimeMismatch, r.checkOut, r.loc)
+	}
+	return nil
+}
+
+// bookable сообщает, можно ли сейчас начать бронь в момент start: срок до заезда, горизонт и день заезда.
+// Используется календарём доступности для отдельных слотов.
+func (r *stayRules) bookable(start, now time.Time) bool {
+	if start.Before(now.Add(r.minNotice)) || (r.horizon > 0 && start.After(now.Add(r.horizon))) {
+		return false
+	}
+	return len(r.weekdays) == 0 || r.weekdays[start.In(r.loc).Weekday()]
+}
+
+// window возвращает промежуток, в котором сейчас может начаться бронь.
+// Без горизонта End — нулевое время: промежуток не ограничен сверху.
+func (r *stayRules) window(now time.Time) timeSlot {
+	w := timeSlot{Start: now.Add(r.minNotice)}
+	if r.horizon > 0 {
+		w.End = now.Add(r.horizon)
+	}
+	return w
+}
+
+// calendarNights — число ночей между датами заезда и выезда по календарю листинга.
+func calendarNights(start, end time.Time) int {
+	y1, m1, d1 := start.Date()
+	y2, m2, d2 := end.Date()
+	from := time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+func loadBookingRules(ctx context.Context, repo *repository.PolicyRepository, listingID string) (*model.BookingRules, error) {
+	br, err := repo.GetBookingRules(ctx, listingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &model.BookingRules{ListingID: listingID, CheckInWeekdays: []int64{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return br, nil
+}
+
+// validateBookingRules проверяет правила, заданные владельцем листинга.
+func validateBookingRules(br *model.BookingRules) error {
+	for _, v := range []int{br.MinDurationMinutes, br.MaxDurationMinutes, br.MinNoticeMinutes} {
+		if v < 0 || v > maxRuleMinutes {
+			return fmt.Errorf("%w: durations must be between 0 and %d minutes", ErrInvalidBookingRules, maxRuleMinutes)
+		}
+	}
+	if br.MaxDurationMinutes > 0 && br.MaxDurationMinutes < br.MinDurationMinutes {
+		return fmt.Errorf("%w: max_duration_minutes must not be less than min_duration_minutes", ErrInvalidBookingRules)
+	}
+	if br.MaxHorizonDays < 0 || br.MaxHorizonDays > maxHorizonDays {
+		return fmt.Errorf("%w: max_horizon_days must be between 0 and %d", ErrInvalidBookingRules, maxHorizonDays)
+	}
+	if br.BeforeMinutes < 0 || br.BeforeMinutes > maxBufferMinutes || br.AfterMinutes < 0 || br.AfterMinutes > maxBufferMinutes {
+		return fmt.Errorf("%w: buffers must be between 0 and %d minutes", ErrInvalidBookingRules, maxBufferMinutes)
+	}
+	if br.CheckInWeekdays == nil {
+		br.CheckInWeekdays = []int64{}
+	}
+	seen := map[int64]bool{}
+	for _, d := range br.CheckInWeekdays {
+		if d < int64(time.Sunday) || d > int64(time.Saturday) {
+			return fmt.Errorf("%w: weekday must be between 0 (Sunday) and 6 (Saturday)", ErrInvalidBookingRules)
+		}
+		if seen[d] {
+			return fmt.Errorf("%w: duplicate weekday %d", ErrInvalidBookingRules, d)
+		}
+		seen[d] = true
+	}
+	for name, t := range map[string]*string{"check_in_time": br.CheckInTime, "check_out_time": br.CheckOutTime} {
+		if t == nil {
+			continue
+		}
+		if m, err := parseClock(*t); err != nil || m >= 24*60 {
+			return fmt.Errorf("%w: %s must be HH:MM", ErrInvalidBookingRules, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"booking-service/internal/model"
+)
+
+func TestStayRulesCheck(t *testing.T) {
+	almaty := mustLoc(t, "Asia/Almaty")
+	// 2 июня 2025 — понедельник; now — 11:00 по Алматы
+	local := func(day, hour int) time.Time { return time.Date(2025, 6, day, hour, 0, 0, 0, almaty) }
+	now := local(2, 11)
+
+	cases := []struct {
+		name         string
+		rules        stayRules
+		start, end   time.Time
+		startChanged bool
+		want         error
+	}{
+		{"no rules: one minute", stayRules{}, local(3, 10), local(3, 10).Add(time.Minute), true, nil},
+		{"no rules: years ahead", stayRules{}, local(2, 12).AddDate(5, 0, 0), local(2, 12).AddDate(5, 0, 1), true, nil},
+		{"start in the past", stayRules{}, local(2, 10), local(2, 14), true, ErrStartInPast},
+		{"past start kept on modification", stayRules{}, local(2, 10), local(2, 14), false, nil},
+
+		{"notice met", stayRules{minNotice: 2 * time.Hour}, local(2, 13), local(2, 15), true, nil},
+		{"notice too short", stayRules{minNotice: 2 * time.Hour}, local(2, 12), local(2, 15), true, ErrInsufficientNotice},
+		{"notice ignored when start is kept", stayRules{minNotice: 2 * time.Hour}, local(2, 12), local(2, 15), false, nil},
+
+		{"exactly at the horizon", stayRules{horizon: 30 * 24 * time.Hour}, local(2, 11).AddDate(0, 0, 30), local(2, 12).AddDate(0, 0, 30), true, nil},
+		{"beyond the horizon", stayRules{horizon: 30 * 24 * time.Hour}, local(2, 12).AddDate(0, 0, 30), local(2, 13).AddDate(0, 0, 30), true, ErrBeyondHorizon},
+
+		{"minimum duration met", stayRules{minDuration: 2 * time.Hour}, local(3, 10), local(3, 12), true, nil},
+		{"shorter than minimum", stayRules{minDuration: 2 * time.Hour}, local(3, 10), local(3, 11), true, ErrStayTooShort},
+		{"minimum still applies on modification", stayRules{minDuration: 2 * time.Hour}, local(3, 10), local(3, 11), false, ErrStayTooShort},
+		{"longer than maximum", stayRules{maxDuration: 3 * time.Hour}, local(3, 10), local(3, 14), true, ErrStayTooLong},
+
+		{"minimum nights met", stayRules{minNights: 2}, local(3, 14), local(5, 12), true, nil},
+		{"fewer nights than minimum", stayRules{minNights: 2}, local(3, 14), local(4, 23), true, ErrStayTooShort},
+		{"more nights than maximum", stayRules{maxNights: 2}, local(3, 14), local(6, 12), true, ErrStayTooLong},
+
+		{"allowed check-in day", stayRules{weekdays: map[time.Weekday]bool{time.Friday: true}}, local(6, 14), local(8, 12), true, nil},
+		{"check-in day not allowed", stayRules{weekdays: map[time.Weekday]bool{time.Friday: true}}, local(5, 14), local(8, 12), true, ErrCheckInDayNotAllowed},
+		{
+			// 23:00 четверга по UTC — уже пятница в Алматы
+			"check-in day in listing time zone", stayRules{weekdays: map[time.Weekday]bool{time.Friday: true}},
+			time.Date(2025, 6, 5, 23, 0, 0, 0, time.UTC), local(8, 12), true, nil,
+		},
+		{"check-in day kept on modification", stayRules{weekdays: map[time.Weekday]bool{time.Friday: true}}, local(5, 14), local(8, 12), false, nil},
+
+		{"check-in and check-out times", stayRules{checkIn: "14:00", checkOut: "12:00"}, local(3, 14), local(5, 12), true, nil},
+		{"wrong check-in time", stayRules{checkIn: "14:00"}, local(3, 15), local(5, 12), true, ErrCheckInTimeMismatch},
+		{"wrong check-out time", stayRules{checkOut: "12:00"}, local(3, 14), local(5, 11), true, ErrCheckOutTimeMismatch},
+		{"check-out checked on modification", stayRules{checkIn: "14:00", checkOut: "12:00"}, local(3, 15), local(5, 11), false, ErrCheckOutTimeMismatch},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := c.rules
+			r.loc = almaty
+			err := r.check(c.start, c.end, now, c.startChanged)
+			if c.want == nil && err != nil {
+				t.Fatalf("check = %v, want nil", err)
+			}
+			if c.want != nil && !errors.Is(err, c.want) {
+				t.Fatalf("check = %v, want %v", err, c.want)
+			}
+		})
+	}
+}
+
+func TestStayRulesBookableAndWindow(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	unlimited := &stayRules{loc: time.UTC}
+	if !unlimited.bookable(now.AddDate(10, 0, 0), now) {
+		t.Fatal("start ten years ahead is not bookable without a horizon")
+	}
+	if unlimited.bookable(now.Add(-time.Minute), now) {
+		t.Fatal("start in the past is bookable")
+	}
+	if w := unlimited.window(now); !w.Start.Equal(now) || !w.End.IsZero() {
+		t.Fatalf("window without rules = %v..%v, want %v..unbounded", w.Start, w.End, now)
+	}
+
+	limited := &stayRules{
+		loc:       time.UTC,
+		minNotice: time.Hour,
+		horizon:   7 * 24 * time.Hour,
+		weekdays:  map[time.Weekday]bool{time.Wednesday: true},
+	}
+	for start, want := range map[time.Time]bool{
+		now.Add(30 * time.Minute):              false, // меньше часа до заезда
+		now.Add(25 * time.Hour):                false, // вторник
+		now.Add(49 * time.Hour):                true,  // среда
+		now.Add(7*24*time.Hour + 49*time.Hour): false, // среда за горизонтом
+	} {
+		if got := limited.bookable(start, now); got != want {
+			t.Errorf("bookable(%s) = %v, want %v", start, got, want)
+		}
+	}
+	if w := limited.window(now); !w.Start.Equal(now.Add(time.Hour)) || !w.End.Equal(now.Add(7*24*time.Hour)) {
+		t.Fatalf("window = %v..%v", w.Start, w.End)
+	}
+}
+
+func TestValidateBookingRules(t *testing.T) {
+	valid := func() *model.BookingRules {
+		return &model.BookingRules{
+			MinDurationMinutes: 60,
+			MaxDurationMinutes: 24 * 60,
+			MinNoticeMinutes:   120,
+			MaxHorizonDays:     180,
+			CheckInWeekdays:    []int64{5, 6},
+			CheckInTime:        strptr("14:00"),
+			CheckOutTime:       strptr("11:00"),
+			Buffer:             model.Buffer{BeforeMinutes: 30, AfterMinutes: 60},
+		}
+	}
+	if err := validateBookingRules(valid()); err != nil {
+		t.Fatalf("valid rules rejected: %v", err)
+	}
+	empty := &model.BookingRules{}
+	if err := validateBookingRules(empty); err != nil || empty.CheckInWeekdays == nil {
+		t.Fatalf("empty rules: err=%v, weekdays=%v; want accepted with an empty weekday list", err, empty.CheckInWeekdays)
+	}
+
+	cases := []struct {
+		name   string
+		modify func(br *model.BookingRules)
+	}{
+		{"negative duration", func(br *model.BookingRules) { br.MinDurationMinutes = -1 }},
+		{"duration above a year", func(br *model.BookingRules) { br.MaxDurationMinutes = maxRuleMinutes + 1 }},
+		{"negative notice", func(br *model.BookingRules) { br.MinNoticeMinutes = -1 }},
+		{"maximum below minimum", func(br *model.BookingRules) { br.MinDurationMinutes, br.MaxDurationMinutes = 120, 60 }},
+		{"negative horizon", func(br *model.BookingRules) { br.MaxHorizonDays = -1 }},
+		{"horizon too far", func(br *model.BookingRules) { br.MaxHorizonDays = maxHorizonDays + 1 }},
+		{"negative buffer", func(br *model.BookingRules) { br.BeforeMinutes = -1 }},
+		{"buffer above a week", func(br *model.BookingRules) { br.AfterMinutes = maxBufferMinutes + 1 }},
+		{"weekday out of range", func(br *model.BookingRules) { br.CheckInWeekdays = []int64{7} }},
+		{"duplicate weekday", func(br *model.BookingRules) { br.CheckInWeekdays = []int64{1, 1} }},
+		{"malformed check-in time", func(br *model.BookingRules) { br.CheckInTime = strptr("2pm") }},
+		{"check-out at 24:00", func(br *model.BookingRules) { br.CheckOutTime = strptr("24:00") }},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			br := valid()
+			c.modify(br)
+			if err := validateBookingRules(br); !errors.Is(err, ErrInvalidBookingRules) {
+				t.Fatalf("validateBookingRules = %v, want %v", err, ErrInvalidBookingRules)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"booking-service/internal/clients"
+	"booking-service/internal/model"
+	"booking-service/internal/repository"
+)
+
+// Расписание по умолчанию для листингов без собственного: ежедневно 09:00–22:00, слоты по часу.
+const (
+	defaultSlotMinutes = 60
+	defaultOpen        = "09:00"
+	defaultClose       = "22:00"
+	defaultTimeZone    = "UTC"
+)
+
+type ScheduleService struct {
+	repo     *repository.ScheduleRepository
+	listings clients.ListingClient
+}
+
+func NewScheduleService(repo *repository.ScheduleRepository, listings clients.ListingClient) *ScheduleService {
+	return &ScheduleService{repo: repo, listings: listings}
+}
+
+// GetSchedule возвращает расписание листинга или расписание по умолчанию, если оно не задано.
+func (s *ScheduleService) GetSchedule(ctx context.Context, listingID string) (*model.ListingSchedule, error) {
+	return loadSchedule(ctx, s.repo, listingID)
+}
+
+// SaveSchedule проверяет и сохраняет расписание листинга. Менять его может владелец листинга или администратор.
+func (s *ScheduleService) SaveSchedule(ctx context.Context, sch *model.ListingSchedule, actor Actor) error {
+	if err := authorizeListingOwner(ctx, s.listings, sch.ListingID, actor); err != nil {
+		return err
+	}
+	if err := validateSchedule(sch); err != nil {
+		return err
+	}
+	sort.SliceStable(sch.WeeklyHours, func(i, j int) bool {
+		a, b := sch.WeeklyHours[i], sch.WeeklyHours[j]
+		if a.Weekday != b.Weekday {
+			return a.Weekday < b.Weekday
+		}
+		return a.Open < b.Open
+	})
+	return s.repo.UpsertSchedule(ctx, sch)
+}
+
+// DeleteSchedule сбрасывает расписание листинга на расписание по умолчанию.
+func (s *ScheduleService) DeleteSchedule(ctx context.Context, listingID string, actor Actor) error {
+	if err := authorizeListingOwner(ctx, s.listings, listingID, actor); err != nil {
+		return err
+	}
+	return s.repo.DeleteSchedule(ctx, listingID)
+}
+
+// ListClosures возвращает закрытые и праздничные дни листинга в диапазоне [from, to] (YYYY-MM-DD).
+func (s *ScheduleService) ListClosures(ctx context.Context, listingID, from, to string) ([]model.ListingClosure, error) {
+	fromDate, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid 'from' date", ErrInvalidSchedule)
+	}
+	toDate, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid 'to' date", ErrInvalidSchedule)
+	}
+	if toDate.Before(fromDate) {
+		return nil, fmt.Errorf("%w: 'to' must not be before 'from'", ErrInvalidSchedule)
+	}
+	return s.repo.ListClosures(ctx, listingID, from, to)
+}
+
+// SaveClosure проверяет и сохраняет закрытый/праздничный день листинга.
+func (s *ScheduleService) SaveClosure(ctx context.Context, c *model.ListingClosure, actor Actor) error {
+	if err := authorizeListingOwner(ctx, s.listings, c.ListingID, actor); err != nil {
+		return err
+	}
+	if err := validateClosure(c); err != nil {
+		return err
+	}
+	return s.repo.UpsertClosure(ctx, c)
+}
+
+// DeleteClosure удаляет закрытый/праздничный день листинга.
+func (s *ScheduleService) DeleteClosure(ctx context.Context, listingID, date string, actor Actor) error {
+	if err := authorizeListingOwner(ctx, s.listings, listingID, actor); err != nil {
+		return err
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return fmt.Errorf("%w: invalid date", ErrInvalidSchedule)
+	}
+	return s.repo.DeleteClosure(ctx, listingID, date)
+}
+
+// loadSchedule читает расписание листинга, подставляя расписание по умолчанию, если его нет.
+func loadSchedule(ctx context.Context, repo *repository.ScheduleRepository, listingID string) (*model.ListingSchedule, error) {
+	sch, err := repo.GetSchedule(ctx, listingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return defaultSchedule(listingID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sch, nil
+}
+
+func defaultSchedule(listingID string) *model.ListingSchedule {
+	sch := &model.ListingSchedule{ListingID: listingID, SlotMinutes: defaultSlotMinutes, TimeZone: defaultTimeZone}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		sch.WeeklyHours = append(sch.WeeklyHours, model.OpeningHours{Weekday: d, Open: defaultOpen, Close: defaultClose})
+	}
+	return sch
+}
+
+func validateSchedule(sch *model.ListingSchedule) error {
+	if sch.TimeZone == "" {
+		sch.TimeZone = defaultTimeZone
+	}
+	if _, err := loadLocation(sch.TimeZone); err != nil {
+		return err
+	}
+	if sch.SlotMinutes < 5 || sch.SlotMinutes > 24*60 {
+		return fmt.Errorf("%w: slot_minutes must be between 5 and 1440", ErrInvalidSchedule)
+	}
+
+	byDay := map[time.Weekday][][2]int{}
+	for _, h := range sch.WeeklyHours {
+		if h.Weekday < time.Sunday || h.Weekday > time.Saturday {
+			return fmt.Errorf("%w: weekday must be between 0 (Sunday) and 6 (Saturday)", ErrInvalidSchedule)
+		}
+		openMin, closeMin, err := parseInterval(h.Open, h.Close)
+		if err != nil {
+			return err
+		}
+		for _, iv := range byDay[h.Weekday] {
+			if openMin < iv[1] && iv[0] < closeMin {
+				return fmt.Errorf("%w: overlapping hours on weekday %d", ErrInvalidSchedule, h.Weekday)
+			}
+		}
+		byDay[h.Weekday] = append(byDay[h.Weekday], [2]int{openMin, closeMin})
+	}
+	return nil
+}
+
+func validateClosure(c *model.ListingClosure) error {
+	if _, err := time.Parse("2006-01-02", c.Date); err != nil {
+		return fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidSchedule)
+	}
+	switch c.Kind {
+	case model.ClosureBlackout:
+		if c.Open != nil || c.Close != nil {
+			return fmt.Errorf("%w: blackout days cannot have opening hours", ErrInvalidSchedule)
+		}
+	case model.ClosureHoliday:
+		if (c.Open == nil) != (c.Close == nil) {
+			return fmt.Errorf("%w: holiday hours need both open and close", ErrInvalidSchedule)
+		}
+		if c.Open != nil {
+			if _, _, err := parseInterval(*c.Open, *c.Close); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: kind must be %s or %s", ErrInvalidSchedule, model.ClosureBlackout, model.ClosureHoliday)
+	}
+	return nil
+}
+
+// loadLocation загружает IANA-часовой пояс, возвращая ErrInvalidTimeZone для неизвестных имён.
+func loadLocation(name string) (*time.Location, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimeZone, name)
+	}
+	return loc, nil
+}
+
+// parseInterval разбирает пару "HH:MM" в минуты от начала дня и проверяет open < close.
+func parseInterval(openStr, closeStr string) (int, int, error) {
+	openMin, err := parseClock(openStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	closeMin, err := parseClock(closeStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if openMin >= closeMin {
+		return 0, 0, fmt.Errorf("%w: open %s must be before close %s", ErrInvalidSchedule, openStr, closeStr)
+	}
+	return openMin, closeMin, nil
+}
+
+// parseClock переводит "HH:MM" (от "00:00" до "24:00") в минуты от начала дня.
+func parseClock(s string) (int, error) {
+	var h, m int
+	if n, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || n != 2 || len(s) != 5 {
+		return 0, fmt.Errorf("%w: time %q must be HH:MM", ErrInvalidSchedule, s)
+	}
+	if h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("%w: time %q is out of range", ErrInvalidSchedule, s)
+	}
+	return h*60 + m, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"booking-service/internal/model"
+	"booking-service/internal/repository"
+	"booking-service/internal/webhooks"
+)
+
+const (
+	minWebhookSecretLen = 16
+	maxDeliveriesList   = 100
+)
+
+// WebhookSubscriptionRequest — тело создания/изменения подписки. Пустой Secret при создании
+// означает «сгенерировать», при изменении — «оставить прежний». Active по умолчанию true.
+type WebhookSubscriptionRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+	Active     *bool    `json:"active"`
+}
+
+type WebhookService struct {
+	repo *repository.WebhookRepository
+}
+
+func NewWebhookService(repo *repository.WebhookRepository) *WebhookService {
+	return &WebhookService{repo: repo}
+}
+
+// CreateSubscription создаёт подписку actor на события броней его листингов.
+// Секрет возвращается в ответе только здесь и при смене секрета.
+func (s *WebhookService) CreateSubscription(ctx context.Context, actor Actor, req WebhookSubscriptionRequest) (*model.WebhookSubscription, error) {
+	if err := validateWebhook(ctx, &req); err != nil {
+		return nil, err
+	}
+	sub := &model.WebhookSubscription{
+		OwnerID:    actor.UserID,
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+		Active:     req.Active == nil || *req.Active,
+	}
+	if sub.Secret == "" {
+		secret, err := webhooks.NewSecret()
+		if err != nil {
+			return nil, fmt.Errorf("WebhookService.CreateSubscription: %w", err)
+		}
+		sub.Secret = secret
+	}
+	if err := s.repo.CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// ListSubscriptions возвращает подписки ownerID. Чужие подписки видит только администратор.
+func (s *WebhookService) ListSubscriptions(ctx context.Context, ownerID string, actor Actor) ([]model.WebhookSubscription, error) {
+	if !actor.Admin && actor.UserID != ownerID {
+		return nil, fmt.Errorf("%w: you can only list your own webhook subscriptions", ErrForbidden)
+	}
+	list, err := s.repo.ListSubscriptions(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Secret = ""
+	}
+	return list, nil
+}
+
+// GetSubscription возвращает подписку без секрета.
+func (s *WebhookService) GetSubscription(ctx context.Context, id string, actor Actor) (*model.WebhookSubscription, error) {
+	sub, err := s.subscription(ctx, id, actor)
+	if err != nil {
+		return nil, err
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+// UpdateSubscription полностью заменяет настройки подписки; секрет меняется, только если передан.
+func (s *WebhookService) UpdateSubscription(ctx context.Context, id string, actor Actor, req WebhookSubscriptionRequest) (*model.WebhookSubscription, error) {
+	sub, err := s.subscription(ctx, id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWebhook(ctx, &req); err != nil {
+		return nil, err
+	}
+	sub.URL = req.URL
+	sub.EventTypes = req.EventTypes
+	sub.Active = req.Active == nil || *req.Active
+	if req.Secret != "" {
+		sub.Secret = req.Secret
+	}
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	if req.Secret == "" {
+		sub.Secret = ""
+	}
+	return sub, nil
+}
+
+// DeleteSubscription удаляет подписку вместе с историей доставок.
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id string, actor Actor) error {
+	if _, err := s.subscription(ctx, id, actor); err != nil {
+		return err
+	}
+	return s.repo.DeleteSubscription(ctx, id)
+}
+
+// ListDeliveries возвращает последние доставки подписки.
+func (s *WebhookService) ListDeliveries(ctx context.Context, subscriptionID string, actor Actor) ([]model.WebhookDelivery, error) {
+	if _, err := s.subscription(ctx, subscriptionID, actor); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, subscriptionID, maxDeliveriesList)
+}
+
+// GetDelivery возвращает доставку с журналом попыток.
+func (s *WebhookService) GetDelivery(ctx context.Context, deliveryID string, actor Actor) (*model.WebhookDelivery, error) {
+	d, err := s.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.subscription(ctx, d.SubscriptionID, actor); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Redeliver ставит доставку в очередь повторно — например, после того как получатель починил свой endpoint.
+func (s *WebhookService) Redeliver(ctx context.Context, deliveryID string, actor Actor) (*model.WebhookDelivery, error) {
+	if _, err := s.GetDelivery(ctx, deliveryID, actor); err != nil {
+		return nil, err
+	}
+	return s.repo.Redeliver(ctx, deliveryID)
+}
+
+// subscription загружает подписку и проверяет, что actor — её владелец или администратор.
+func (s *WebhookService) subscription(ctx context.Context, id string, actor Actor) (*model.WebhookSubscription, error) {
+	sub, err := s.repo.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Admin && sub.OwnerID != actor.UserID {
+		return nil, fmt.Errorf("%w: not your webhook subscription", ErrForbidden)
+	}
+	return sub, nil
+}
+
+// validateWebhook проверяет URL (в том числе что он ведёт на публичный адрес), типы событий
+// (убирая дубликаты) и секрет.
+func validateWebhook(ctx context.Context, req *WebhookSubscriptionRequest) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+	if err := webhooks.CheckTarget(ctx, u.Hostname()); err != nil {
+		return fmt.Errorf("%w: url must point to a public address: %v", ErrInvalidWebhook, err)
+	}
+	if len(req.EventTypes) == 0 {
+		return fmt.Errorf("%w: event_types must not be empty", ErrInvalidWebhook)
+	}
+	for _, t := range req.EventTypes {
+		if !slices.Contains(model.BookingEventTypes, t) {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, t)
+		}
+	}
+	slices.Sort(req.EventTypes)
+	req.EventTypes = slices.Compact(req.EventTypes)
+	if req.Secret != "" && len(req.Secret) < minWebhookSecretLen {
+		return fmt.Errorf("%w: secret must be at least %d characters", ErrInvalidWebhook, minWebhookSecretLen)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+// Package testdb подключает интеграционные тесты к тестовой базе Postgres.
+package testdb
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"booking-service/internal/migrations"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+// Open подключается к Postgres из TEST_DATABASE_URL и накатывает миграции.
+// Без переменной тест пропускается: локальной базы в CI может не быть.
+func Open(t *testing.T) *sqlx.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := migrations.New(db)
+	if err != nil {
+		t.Fatalf("migrations: %v", err)
+	}
+	if err := m.Up(context.Background()); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,35 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"booking-service/internal/model"
+	"booking-service/internal/repository"
+)
+
+// Dispatcher — EventPublisher для outbox relay: раскладывает событие брони по доставкам
+// в подписки владельца листинга. Сами запросы отправляет Worker.
+type Dispatcher struct {
+	repo *repository.WebhookRepository
+}
+
+func NewDispatcher(repo *repository.WebhookRepository) *Dispatcher {
+	return &Dispatcher{repo: repo}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, e model.OutboxEvent) error {
+	var b model.Booking
+	if err := json.Unmarshal(e.Payload, &b); err != nil {
+		return fmt.Errorf("Dispatcher.Publish: decode booking: %w", err)
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("Dispatcher.Publish: %w", err)
+	}
+	if _, err := d.repo.EnqueueDeliveries(ctx, b.OwnerID, e.ID, e.EventType, body); err != nil {
+		return fmt.Errorf("Dispatcher.Publish: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package webhooks
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	const secret = "whsec_test_secret"
+	ts := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"id":42,"type":"BookingCreated"}`)
+
+	sig := Sign(secret, ts, body)
+	if !strings.HasPrefix(sig, "sha256=") {
+		t.Fatalf("signature %q has no sha256= prefix", sig)
+	}
+	if !Verify(secret, ts, body, sig) {
+		t.Fatal("valid signature rejected")
+	}
+
+	cases := []struct {
+		name   string
+		secret string
+		ts     time.Time
+		body   []byte
+		sig    string
+	}{
+		{"tampered body", secret, ts, []byte(`{"id":43,"type":"BookingCreated"}`), sig},
+		{"tampered timestamp", secret, ts.Add(time.Second), body, sig},
+		{"other secret", "whsec_other_secret", ts, body, sig},
+		{"truncated signature", secret, ts, body, sig[:len(sig)-1]},
+		{"empty signature", secret, ts, body, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if Verify(c.secret, c.ts, c.body, c.sig) {
+				t.Fatal("invalid signature accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenTarget возвращается для адресов, на которые вебхуки не отправляются: loopback,
+// частные сети и link-local (в том числе 169.254.169.254 — метаданные облака). Иначе владелец
+// листинга мог бы заставить сервис ходить во внутреннюю сеть и читать ответы в журнале доставок.
+var ErrForbiddenTarget = errors.New("webhook target address is not allowed")
+
+// sharedAddressSpace — 100.64.0.0/10 (RFC 6598, CGNAT): внутренний адрес, хотя IsPrivate его не считает.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicAddr сообщает, можно ли отправлять вебхук на адрес ip.
+func publicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// CheckTarget проверяет при сохранении подписки, что host (имя или IP из URL) указывает только
+// на публичные адреса. Имя могут позже перенаправить на внутренний адрес (DNS rebinding), поэтому
+// при каждой отправке адрес проверяется ещё раз — см. newDeliveryClient.
+func CheckTarget(ctx context.Context, host string) error {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if !publicAddr(ip) {
+			return ErrForbiddenTarget
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", host, err)
+	}
+	for _, ip := range addrs {
+		if !publicAddr(ip) {
+			return ErrForbiddenTarget
+		}
+	}
+	return nil
+}
+
+// newDeliveryClient возвращает HTTP-клиент, который соединяется только с публичными адресами.
+// Проверка стоит в Control диалера, то есть после разрешения имени и для каждого соединения,
+// включая редиректы. Прокси из окружения не используется: через него проверка бы не работала.
+func newDeliveryClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil || !publicAddr(ip) {
+				return ErrForbiddenTarget
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: deliveryTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        20,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
@@ -0,0 +1,151 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"booking-service/internal/model"
+)
+
+const (
+	workerBatchSize = 50
+	deliveryTimeout = 10 * time.Second
+	maxBackoff      = 6 * time.Hour
+)
+
+// DeliveryStore — очередь доставок вебхуков. Реализуется repository.WebhookRepository.
+type DeliveryStore interface {
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]model.DueDelivery, error)
+	RecordAttempt(ctx context.Context, a *model.WebhookDeliveryAttempt, status string, nextAttemptAt *time.Time) error
+}
+
+// Worker отправляет доставки вебхуков и повторяет неудачные с экспоненциальной задержкой:
+// retryBase, 2·retryBase, 4·retryBase, … (не больше maxBackoff) плюс до 20% случайного разброса.
+// После maxAttempts неудачных попыток доставка получает статус FAILED.
+type Worker struct {
+	repo        DeliveryStore
+	httpClient  *http.Client
+	interval    time.Duration
+	retryBase   time.Duration
+	maxAttempts int
+}
+
+func NewWorker(repo DeliveryStore, interval, retryBase time.Duration, maxAttempts int) *Worker {
+	return &Worker{
+		repo:        repo,
+		httpClient:  newDeliveryClient(),
+		interval:    interval,
+		retryBase:   retryBase,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Run отправляет доставки каждые interval, пока не отменён ctx.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		n, err := w.RunOnce(ctx)
+		if err != nil {
+			log.Printf("webhook worker: %v\n", err)
+		}
+		if err == nil && n == workerBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce отправляет один батч доставок, которым пора уходить, и возвращает их количество.
+func (w *Worker) RunOnce(ctx context.Context) (int, error) {
+	// Lease с запасом на таймаут запроса: пока воркер работает, другие эти доставки не возьмут
+	due, err := w.repo.ClaimDue(ctx, workerBatchSize, deliveryTimeout*workerBatchSize+time.Minute)
+	if err != nil {
+		return 0, err
+	}
+	for i := range due {
+		if err := w.deliver(ctx, &due[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(due), nil
+}
+
+// deliver выполняет одну попытку и сохраняет её результат.
+func (w *Worker) deliver(ctx context.Context, d *model.DueDelivery) error {
+	started := time.Now()
+	statusCode, sendErr := w.send(ctx, d, started)
+
+	attempt := &model.WebhookDeliveryAttempt{
+		DeliveryID: d.ID,
+		DurationMS: int(time.Since(started) / time.Millisecond),
+	}
+	if statusCode != 0 {
+		attempt.StatusCode = &statusCode
+	}
+
+	status := model.DeliverySucceeded
+	var next *time.Time
+	if sendErr != nil {
+		msg := sendErr.Error()
+		attempt.Error = &msg
+
+		attempts := d.Attempts + 1
+		if attempts >= w.maxAttempts {
+			status = model.DeliveryFailed
+		} else {
+			status = model.DeliveryPending
+			at := time.Now().Add(w.backoff(attempts))
+			next = &at
+		}
+	}
+	return w.repo.RecordAttempt(ctx, attempt, status, next)
+}
+
+// send отправляет подписанный запрос. Успех — любой ответ 2xx.
+func (w *Worker) send(ctx context.Context, d *model.DueDelivery, ts time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderDelivery, d.ID)
+	req.Header.Set(HeaderTimestamp, fmt.Sprint(ts.Unix()))
+	req.Header.Set(HeaderSignature, Sign(d.Secret, ts, d.Payload))
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff возвращает задержку перед попыткой номер attempts+1.
+func (w *Worker) backoff(attempts int) time.Duration {
+	d := w.retryBase
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d + rand.N(d/5+1)
+}
@@ -0,0 +1,222 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"booking-service/internal/model"
+)
+
+// memoryStore — DeliveryStore в памяти: отдаёт доставки в статусе PENDING независимо от времени
+// следующей попытки (время проверяет сам тест) и запоминает записанные попытки.
+type memoryStore struct {
+	mu         sync.Mutex
+	deliveries []*model.DueDelivery
+	attempts   []recordedAttempt
+}
+
+type recordedAttempt struct {
+	attempt model.WebhookDeliveryAttempt
+	status  string
+	next    *time.Time
+}
+
+func (s *memoryStore) ClaimDue(_ context.Context, limit int, _ time.Duration) ([]model.DueDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []model.DueDelivery
+	for _, d := range s.deliveries {
+		if d.Status == model.DeliveryPending && len(due) < limit {
+			due = append(due, *d)
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryStore) RecordAttempt(_ context.Context, a *model.WebhookDeliveryAttempt, status string, next *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.deliveries {
+		if d.ID == a.DeliveryID {
+			d.Attempts++
+			d.Status = status
+			d.NextAttemptAt = next
+		}
+	}
+	s.attempts = append(s.attempts, recordedAttempt{attempt: *a, status: status, next: next})
+	return nil
+}
+
+func newDelivery(url string) *model.DueDelivery {
+	return &model.DueDelivery{
+		WebhookDelivery: model.WebhookDelivery{
+			ID:        "delivery-1",
+			EventType: model.EventBookingCreated,
+			Payload:   []byte(`{"id":1,"type":"BookingCreated"}`),
+			Status:    model.DeliveryPending,
+		},
+		URL:    url,
+		Secret: "whsec_test_secret",
+	}
+}
+
+func TestWorkerRetriesWithBackoff(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		unix, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if !Verify("whsec_test_secret", time.Unix(unix, 0), body, r.Header.Get(HeaderSignature)) {
+			t.Errorf("request with invalid signature")
+		}
+
+		mu.Lock()
+		requests++
+		n := requests
+		mu.Unlock()
+		if n == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	const retryBase = time.Minute
+	store := &memoryStore{deliveries: []*model.DueDelivery{newDelivery(srv.URL)}}
+	w := NewWorker(store, time.Second, retryBase, 5)
+	w.httpClient = srv.Client() // обычный клиент: защитный не пустит на 127.0.0.1
+	ctx := context.Background()
+
+	// 1-я попытка: 500 — доставка остаётся PENDING и откладывается на retryBase (+ до 20%)
+	before := time.Now()
+	if n, err := w.RunOnce(ctx); err != nil || n != 1 {
+		t.Fatalf("first run: n=%d err=%v", n, err)
+	}
+	if len(store.attempts) != 1 {
+		t.Fatalf("recorded %d attempts, want 1", len(store.attempts))
+	}
+	first := store.attempts[0]
+	if first.status != model.DeliveryPending {
+		t.Fatalf("status after 500 = %s, want %s", first.status, model.DeliveryPending)
+	}
+	if first.attempt.StatusCode == nil || *first.attempt.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status code = %v, want 500", first.attempt.StatusCode)
+	}
+	if first.attempt.Error == nil {
+		t.Fatal("failed attempt has no error")
+	}
+	if first.next == nil {
+		t.Fatal("failed attempt has no next attempt time")
+	}
+	earliest, latest := before.Add(retryBase), time.Now().Add(retryBase+retryBase/5+time.Nanosecond)
+	if first.next.Before(earliest) || first.next.After(latest) {
+		t.Fatalf("next attempt at %s, want between %s and %s", first.next, earliest, latest)
+	}
+
+	// 2-я попытка: 200 — доставка успешна, повторов больше нет
+	if n, err := w.RunOnce(ctx); err != nil || n != 1 {
+		t.Fatalf("second run: n=%d err=%v", n, err)
+	}
+	if len(store.attempts) != 2 {
+		t.Fatalf("recorded %d attempts, want 2", len(store.attempts))
+	}
+	second := store.attempts[1]
+	if second.status != model.DeliverySucceeded || second.next != nil || second.attempt.Error != nil {
+		t.Fatalf("second attempt = %+v, want SUCCEEDED without retry", second)
+	}
+	if second.attempt.StatusCode == nil || *second.attempt.StatusCode != http.StatusOK {
+		t.Fatalf("status code = %v, want 200", second.attempt.StatusCode)
+	}
+
+	if n, err := w.RunOnce(ctx); err != nil || n != 0 {
+		t.Fatalf("third run: n=%d err=%v, want nothing to deliver", n, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 2 {
+		t.Fatalf("receiver got %d requests, want 2", requests)
+	}
+}
+
+func TestWorkerGivesUpAfterMaxAttempts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	store := &memoryStore{deliveries: []*model.DueDelivery{newDelivery(srv.URL)}}
+	w := NewWorker(store, time.Second, time.Minute, 2)
+	w.httpClient = srv.Client()
+
+	for i := 0; i < 2; i++ {
+		if _, err := w.RunOnce(context.Background()); err != nil {
+			t.Fatalf("run %d: %v", i+1, err)
+		}
+	}
+	last := store.attempts[len(store.attempts)-1]
+	if last.status != model.DeliveryFailed || last.next != nil {
+		t.Fatalf("last attempt = %+v, want FAILED without retry", last)
+	}
+}
+
+func TestWorkerRefusesPrivateTargets(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	// Защитный клиент по умолчанию: httptest слушает 127.0.0.1
+	store := &memoryStore{deliveries: []*model.DueDelivery{newDelivery(srv.URL)}}
+	w := NewWorker(store, time.Second, time.Minute, 5)
+	if _, err := w.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if called {
+		t.Fatal("worker delivered to a loopback address")
+	}
+	a := store.attempts[0]
+	if a.attempt.Error == nil || !strings.Contains(*a.attempt.Error, ErrForbiddenTarget.Error()) {
+		t.Fatalf("attempt error = %v, want %v", a.attempt.Error, ErrForbiddenTarget)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	w := &Worker{retryBase: time.Minute}
+	for attempts, want := range map[int]time.Duration{
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		3:  4 * time.Minute,
+		4:  8 * time.Minute,
+		20: maxBackoff,
+	} {
+		got := w.backoff(attempts)
+		if got < want || got > want+want/5 {
+			t.Errorf("backoff(%d) = %s, want %s..%s", attempts, got, want, want+want/5)
+		}
+	}
+}
+
+func TestCheckTarget(t *testing.T) {
+	for _, host := range []string{"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "fe80::1", "fd00::1", "100.64.0.1", "0.0.0.0", "::ffff:127.0.0.1"} {
+		if err := CheckTarget(context.Background(), host); !errors.Is(err, ErrForbiddenTarget) {
+			t.Errorf("CheckTarget(%s) = %v, want %v", host, err, ErrForbiddenTarget)
+		}
+	}
+	for _, host := range []string{"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"} {
+		if err := CheckTarget(context.Background(), host); err != nil {
+			t.Errorf("CheckTarget(%s) = %v, want nil", host, err)
+		}
+	}
+}
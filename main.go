@@ -1,17 +1,26 @@
 package main
 
 import (
+	"context"
+	"expvar"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
+	_ "time/tzdata" // в alpine-образе нет /usr/share/zoneinfo, а часовые пояса листингов нужны всегда
 
+	"booking-service/internal/clients"
 	"booking-service/internal/config"
+	"booking-service/internal/events"
 	"booking-service/internal/handler"
 	"booking-service/internal/middleware"
+	"booking-service/internal/migrations"
+	"booking-service/internal/payments"
 	"booking-service/internal/repository"
 	"booking-service/internal/service"
+	"booking-service/internal/webhooks"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jmoiron/sqlx"
@@ -35,33 +44,118 @@ func main() {
 	db.SetConnMaxLifetime(5 * time.Minute)
 	log.Println("Connected to Postgres")
 
+	// Подкоманда `booking-service migrate up|down [steps]` — только миграции, без HTTP-сервера
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+	if cfg.MigrateOnStart {
+		if err := runMigrate(db, []string{"up"}); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+	}
+
 	// 2) Инициализируем репозитории, сервисы, хендлеры
 	bookingRepo := repository.NewBookingRepository(db)
-	bookingSvc := service.NewBookingService(
-		bookingRepo,
-		cfg.UserServiceURL,
-		cfg.ListingServiceURL,
+	scheduleRepo := repository.NewScheduleRepository(db)
+	policyRepo := repository.NewPolicyRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	clientOpts := clients.DefaultOptions()
+	clientOpts.Timeout = cfg.UpstreamTimeout
+	clientOpts.MaxAttempts = cfg.UpstreamMaxAttempts
+	clientOpts.BreakerThreshold = cfg.UpstreamBreakerThreshold
+	clientOpts.BreakerCooldown = cfg.UpstreamBreakerCooldown
+	userClient := clients.NewCachedUserClient(
+		clients.NewUserClient(cfg.UserServiceURL, clientOpts),
+		clients.CacheOptions{Size: cfg.UserCacheSize, TTL: cfg.UpstreamCacheTTL, NegativeTTL: cfg.UpstreamNegativeTTL},
 	)
-	bookingHandler := handler.NewBookingHandler(bookingSvc)
+	listingClient := clients.NewCachedListingClient(
+		clients.NewListingClient(cfg.ListingServiceURL, clientOpts),
+		clients.CacheOptions{Size: cfg.ListingCacheSize, TTL: cfg.UpstreamCacheTTL, NegativeTTL: cfg.UpstreamNegativeTTL},
+	)
+	// POST /payments/webhook открыт без JWT: без секрета подписи любой мог бы подтвердить или отменить бронь
+	if cfg.PaymentWebhookSecret == "" {
+		log.Fatal("PAYMENT_WEBHOOK_SECRET must be set")
+	}
+	var provider payments.PaymentProvider
+	switch cfg.PaymentProvider {
+	case "fake":
+		// Только для локальной разработки: фейковый провайдер включается явно, PAYMENT_PROVIDER=fake
+		log.Printf("Using fake payment provider (auto-authorize: %t)\n", cfg.PaymentFakeAutoAuthorize)
+		provider = payments.NewFakeProvider(cfg.PaymentWebhookSecret, cfg.PaymentFakeAutoAuthorize)
+	case "":
+		log.Fatal("PAYMENT_PROVIDER must be set (use \"fake\" for local development)")
+	default:
+		log.Fatalf("Unknown PAYMENT_PROVIDER %q", cfg.PaymentProvider)
+	}
+	paymentSvc := service.NewPaymentService(bookingRepo, paymentRepo, provider)
+	bookingSvc := service.NewBookingService(bookingRepo, scheduleRepo, policyRepo, userClient, listingClient, paymentSvc)
+	scheduleSvc := service.NewScheduleService(scheduleRepo, listingClient)
+	policySvc := service.NewPolicyService(policyRepo, listingClient)
+	webhookSvc := service.NewWebhookService(webhookRepo)
+	bookingHandler := handler.NewBookingHandler(
+		bookingSvc,
+		middleware.Idempotency(idempotencyRepo, cfg.IdempotencyTTL),
+	)
+	scheduleHandler := handler.NewScheduleHandler(scheduleSvc)
+	policyHandler := handler.NewPolicyHandler(policySvc)
+	webhookHandler := handler.NewWebhookHandler(webhookSvc)
+	paymentHandler := handler.NewPaymentHandler(paymentSvc)
+
+	// Фоновая очистка истёкших ключей идемпотентности
+	go middleware.SweepIdempotencyKeys(context.Background(), idempotencyRepo, cfg.IdempotencySweepInterval)
+
+	// Публикация доменных событий из outbox: в подписки владельцев и, если задан, в EVENTS_WEBHOOK_URL
+	publishers := events.MultiPublisher{webhooks.NewDispatcher(webhookRepo)}
+	if cfg.EventsWebhookURL != "" {
+		publishers = append(publishers, events.NewWebhookPublisher(cfg.EventsWebhookURL))
+	}
+	go events.NewRelay(outboxRepo, publishers, cfg.OutboxPollInterval).Run(context.Background())
+	go webhooks.NewWorker(webhookRepo, cfg.WebhookPollInterval, cfg.WebhookRetryBase, cfg.WebhookMaxAttempts).
+		Run(context.Background())
+
+	// Истечение неоплаченных броней: освобождает слоты и снимает блокировки оплаты
+	go paymentSvc.RunExpiry(context.Background(), cfg.PaymentTTL, cfg.PaymentExpiryInterval)
 
 	r := chi.NewRouter()
 
 	// 🔥 Добавляем CORS middleware
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:63342"}, // Swagger UI
-		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", middleware.IdempotencyKeyHeader, middleware.RequestIDHeader},
+		ExposedHeaders:   []string{middleware.RequestIDHeader},
 		AllowCredentials: true,
 	})
 	r.Use(c.Handler) // 👈 Вот здесь он цепляется
 
+	// ID запроса и IP клиента — для журнала изменений броней
+	r.Use(middleware.RequestContext(cfg.TrustedProxies))
+
 	// JWT middleware + маршруты
 	r.Group(func(r chi.Router) {
 		r.Use(func(next http.Handler) http.Handler {
 			return middleware.JWTAuthMiddleware(next, cfg.JWTSecret)
 		})
 		bookingHandler.RegisterRoutes(r)
+		scheduleHandler.RegisterRoutes(r)
+		policyHandler.RegisterRoutes(r)
+		webhookHandler.RegisterRoutes(r)
+		paymentHandler.RegisterRoutes(r)
+
+		// Счётчики expvar (в т.ч. upstream_cache: попадания и промахи кэша user/listing).
+		// Только для администраторов: кроме метрик там cmdline и memstats процесса.
+		r.With(middleware.RequireRole(middleware.RoleAdmin)).Handle("/debug/vars", expvar.Handler())
 	})
 
+	// Уведомления платёжного провайдера — без JWT, с проверкой подписи
+	paymentHandler.RegisterWebhookRoutes(r)
+
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"status":"ok"}`))
@@ -82,3 +176,31 @@ func main() {
 		log.Fatalf("HTTP server error: %v", err)
 	}
 }
+
+// runMigrate выполняет `up` или `down [steps]` (по умолчанию откатывается одна миграция).
+func runMigrate(db *sqlx.DB, args []string) error {
+	m, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	cmd := "up"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+	switch cmd {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid steps %q", args[1])
+			}
+		}
+		return m.Down(ctx, steps)
+	default:
+		return fmt.Errorf("unknown migrate command %q (expected up or down)", cmd)
+	}
+}